@@ -11,4 +11,10 @@ func main() {
 	}
 
 	plugin.Serve(&p)
+
+	// Serve returns once terraform has closed the plugin, the sessions
+	// opened by the provider are not needed anymore. This is best effort,
+	// a plugin killed by terraform never gets here and its sessions expire
+	// on the server.
+	zabbix.Logout()
 }
@@ -0,0 +1,127 @@
+package zabbix
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/logging"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// headerTransport adds static headers to every request, e.g. for an
+// authentication gateway in front of the Zabbix frontend.
+type headerTransport struct {
+	headers   map[string]string
+	transport http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrip must not modify the original request
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header))
+	for name, values := range req.Header {
+		r.Header[name] = append([]string(nil), values...)
+	}
+	for name, value := range t.headers {
+		r.Header.Set(name, value)
+	}
+	return t.transport.RoundTrip(r)
+}
+
+func getTLSConfig(d *schema.ResourceData) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
+	}
+
+	caPEM := []byte(d.Get("ca_pem").(string))
+	if caFile := d.Get("ca_file").(string); caFile != "" {
+		content, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read ca_file: %v", err)
+		}
+		caPEM = append(caPEM, '\n')
+		caPEM = append(caPEM, content...)
+	}
+	if len(caPEM) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("No valid PEM certificate found in ca_file or ca_pem")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	clientCert := d.Get("client_cert").(string)
+	clientKey := d.Get("client_key").(string)
+	if clientCert != "" || clientKey != "" {
+		if clientCert == "" || clientKey == "" {
+			return nil, fmt.Errorf("client_cert and client_key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// newHTTPClient builds the http client used to talk to the Zabbix API from
+// the provider configuration.
+func newHTTPClient(d *schema.ResourceData) (*http.Client, error) {
+	tlsConfig, err := getTLSConfig(d)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if proxyURL := d.Get("proxy_url").(string); proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid proxy_url %q: %v", proxyURL, err)
+		}
+		proxy = http.ProxyURL(u)
+	}
+
+	// Same settings as http.DefaultTransport
+	var transport http.RoundTripper = &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       tlsConfig,
+	}
+
+	headers := d.Get("headers").(map[string]interface{})
+	if len(headers) > 0 {
+		headerTransport := &headerTransport{
+			headers:   make(map[string]string, len(headers)),
+			transport: transport,
+		}
+		for name, value := range headers {
+			headerTransport.headers[name] = value.(string)
+		}
+		transport = headerTransport
+	}
+
+	if logging.IsDebugOrHigher() {
+		transport = logging.NewTransport("Zabbix", transport)
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   time.Duration(d.Get("timeout").(int)) * time.Second,
+	}, nil
+}
@@ -81,10 +81,6 @@ func dataSourceZabbixServerRead(d *schema.ResourceData, meta interface{}) (err e
 		log.Printf("[DEBUG] Forcing Zabbix Server version to %s\n", serverVersion)
 	} else {
 		serverVersion = getZabbixServerVersion(meta)
-		if serverVersion == "" {
-			return fmt.Errorf("Failed to get Zabbix Server version")
-		}
-
 		log.Printf("[DEBUG] Actual Zabbix Server version is %s\n", serverVersion)
 	}
 
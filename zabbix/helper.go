@@ -3,6 +3,8 @@ package zabbix
 import (
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -60,7 +62,7 @@ func deleteRetry(id string, get getParentFunc, delete deleteFunc, api *zabbix.AP
 
 func createRetry(d *schema.ResourceData, meta interface{}, create createFunc, createArg interface{}, read schema.ReadFunc) error {
 	return resource.Retry(time.Minute, func() *resource.RetryError {
-		api := meta.(*zabbix.API)
+		api := meta.(*providerMeta).api
 		id, err := create(createArg, api)
 		if err != nil {
 			if sqlError(err) {
@@ -75,3 +77,180 @@ func createRetry(d *schema.ResourceData, meta interface{}, create createFunc, cr
 		return resource.NonRetryableError(read(d, meta))
 	})
 }
+
+// callCreate calls a create method of the API for the objects not wrapped by
+// go-zabbix-api and returns the ids found in the idKey field of the result.
+func callCreate(api *zabbix.API, method string, objects interface{}, idKey string) ([]string, error) {
+	response, err := api.CallWithError(method, objects)
+	if err != nil {
+		return nil, err
+	}
+
+	result := response.Result.(map[string]interface{})
+	rawIDs, ok := result[idKey].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Expected %s in the result of %s", idKey, method)
+	}
+	ids := make([]string, len(rawIDs))
+	for i, id := range rawIDs {
+		ids[i] = fmt.Sprintf("%v", id)
+	}
+	return ids, nil
+}
+
+// callDeleteByIDs calls a delete method of the API for the objects not wrapped
+// by go-zabbix-api and checks every id was deleted.
+func callDeleteByIDs(api *zabbix.API, method string, ids []string, idKey string) error {
+	deletedIDs, err := callCreate(api, method, ids, idKey)
+	if err != nil {
+		return err
+	}
+	if len(deletedIDs) != len(ids) {
+		return &zabbix.ExpectedMore{Expected: len(ids), Got: len(deletedIDs)}
+	}
+	return nil
+}
+
+// expectOneResult builds the error returned by the go-zabbix-api GetByID
+// methods, which the Exists functions rely on.
+func expectOneResult(got int) error {
+	e := zabbix.ExpectedOneResult(got)
+	return &e
+}
+
+// boolToString converts a terraform boolean to the "0" or "1" string of the API.
+func boolToString(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// stringPointer returns a pointer to s, for the optional fields sent even when
+// empty.
+func stringPointer(s string) *string {
+	return &s
+}
+
+// stringValue returns the value of an optional field, empty when it is unset.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func getStringSet(d *schema.ResourceData, key string) []string {
+	return setToStringSlice(d.Get(key).(*schema.Set))
+}
+
+func setToStringSlice(set *schema.Set) []string {
+	values := make([]string, set.Len())
+	for i, v := range set.List() {
+		values[i] = v.(string)
+	}
+	return values
+}
+
+func listToStringSlice(list []interface{}) []string {
+	values := make([]string, len(list))
+	for i, v := range list {
+		values[i], _ = v.(string)
+	}
+	return values
+}
+
+func stringSliceToInterface(values []string) []interface{} {
+	res := make([]interface{}, len(values))
+	for i, v := range values {
+		res[i] = v
+	}
+	return res
+}
+
+// atoi converts the integers returned as string by the API, empty values
+// are read as 0.
+func atoi(s string) int {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return i
+}
+
+// parseEnum returns the value of an enum given its name or its value, ok is
+// false when the enum has no such name or value.
+func parseEnum(values map[string]int, value string) (int, bool) {
+	if i, ok := values[value]; ok {
+		return i, true
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	for _, known := range values {
+		if known == i {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// enumToInt returns the value of an enum given its name or its value, unknown
+// values are read as 0.
+func enumToInt(values map[string]int, value string) int {
+	i, _ := parseEnum(values, value)
+	return i
+}
+
+// validateEnum accepts the names of an enum and their values.
+func validateEnum(values map[string]int) schema.SchemaValidateFunc {
+	return func(val interface{}, key string) (warns []string, errs []error) {
+		if _, ok := parseEnum(values, val.(string)); !ok {
+			names := make([]string, 0, len(values))
+			for name := range values {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			errs = append(errs, fmt.Errorf("%q, must be one of %s or their values, got %s", key, strings.Join(names, ", "), val.(string)))
+		}
+		return
+	}
+}
+
+// enumStateFunc stores enums given by name as their value, so that switching
+// between the name and the value does not produce a diff.
+func enumStateFunc(values map[string]int) schema.SchemaStateFunc {
+	return func(val interface{}) string {
+		if i, ok := parseEnum(values, val.(string)); ok {
+			return strconv.Itoa(i)
+		}
+		return val.(string)
+	}
+}
+
+// enumName returns the name of the value of an enum returned as string by the
+// API, empty when the value is unknown.
+func enumName(values map[string]int, value string) string {
+	for name, known := range values {
+		if strconv.Itoa(known) == value {
+			return name
+		}
+	}
+	return ""
+}
+
+// floatToString converts a terraform float to the string of the API.
+func floatToString(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// stringToFloat converts the decimals returned as string by the API, empty
+// values are read as 0.
+func stringToFloat(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
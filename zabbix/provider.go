@@ -1,12 +1,12 @@
 package zabbix
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"net/http"
+	"sync"
 
 	"github.com/claranet/go-zabbix-api"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/logging"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	"github.com/mcuadros/go-version"
@@ -17,14 +17,78 @@ func Provider() terraform.ResourceProvider {
 	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"user": &schema.Schema{
-				Type:        schema.TypeString,
-				Required:    true,
-				DefaultFunc: schema.EnvDefaultFunc("ZABBIX_USER", nil),
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("ZABBIX_USER", nil),
+				ConflictsWith: []string{"api_token"},
 			},
 			"password": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				DefaultFunc:   schema.EnvDefaultFunc("ZABBIX_PASSWORD", nil),
+				ConflictsWith: []string{"api_token"},
+			},
+			"api_token": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				DefaultFunc:   schema.EnvDefaultFunc("ZABBIX_API_TOKEN", nil),
+				ConflictsWith: []string{"user", "password"},
+				Description:   "API token used instead of user and password (Zabbix 5.4+).",
+			},
+			"ca_file": &schema.Schema{
 				Type:        schema.TypeString,
-				Required:    true,
-				DefaultFunc: schema.EnvDefaultFunc("ZABBIX_PASSWORD", nil),
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ZABBIX_CA_FILE", ""),
+				Description: "Path to a PEM-encoded CA bundle used to verify the server certificate.",
+			},
+			"ca_pem": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "PEM-encoded CA bundle used to verify the server certificate.",
+			},
+			"client_cert": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ZABBIX_CLIENT_CERT", ""),
+				Description: "Path to a PEM-encoded client certificate for mutual TLS.",
+			},
+			"client_key": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ZABBIX_CLIENT_KEY", ""),
+				Description: "Path to the PEM-encoded private key of the client certificate.",
+			},
+			"insecure_skip_verify": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ZABBIX_INSECURE_SKIP_VERIFY", false),
+				Description: "Disable the verification of the server certificate.",
+			},
+			"timeout": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+				Description: "Timeout of the requests to the API in seconds, 0 means no timeout.",
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(int)
+					if v < 0 {
+						errs = append(errs, fmt.Errorf("%q, must be greater or equal to 0, got %d", key, v))
+					}
+					return
+				},
+			},
+			"proxy_url": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "URL of the HTTP proxy used to reach the API. Proxy environment variables are used when empty.",
+			},
+			"headers": &schema.Schema{
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "Extra headers sent with every request.",
 			},
 			"server_url": &schema.Schema{
 				Type:        schema.TypeString,
@@ -45,8 +109,20 @@ func Provider() terraform.ResourceProvider {
 			"zabbix_template":          resourceZabbixTemplate(),
 			"zabbix_template_link":     resourceZabbixTemplateLink(),
 			"zabbix_lld_rule":          resourceZabbixLLDRule(),
+			"zabbix_lld_rule_link":     resourceZabbixLLDRuleLink(),
 			"zabbix_item_prototype":    resourceZabbixItemPrototype(),
 			"zabbix_trigger_prototype": resourceZabbixTriggerPrototype(),
+			"zabbix_action":            resourceZabbixAction(),
+			"zabbix_media_type":        resourceZabbixMediaType(),
+			"zabbix_maintenance":       resourceZabbixMaintenance(),
+			"zabbix_proxy":             resourceZabbixProxy(),
+			"zabbix_global_macro":      resourceZabbixGlobalMacro(),
+			"zabbix_user":              resourceZabbixUser(),
+			"zabbix_user_group":        resourceZabbixUserGroup(),
+			"zabbix_application":       resourceZabbixApplication(),
+			"zabbix_graph":             resourceZabbixGraph(),
+			"zabbix_graph_prototype":   resourceZabbixGraphPrototype(),
+			"zabbix_web_scenario":      resourceZabbixWebScenario(),
 		},
 	}
 
@@ -68,35 +144,128 @@ func providerConfigure(d *schema.ResourceData, terraformVersion string) (interfa
 
 	api.UserAgent = fmt.Sprintf("HashiCorp/1.0 Terraform/%s", terraformVersion)
 
-	if logging.IsDebugOrHigher() {
-		httpClient := http.Client{}
-		httpClient.Transport = logging.NewTransport("Zabbix", http.DefaultTransport)
-		api.SetClient(&httpClient)
+	httpClient, err := newHTTPClient(d)
+	if err != nil {
+		return nil, err
+	}
+	api.SetClient(httpClient)
+
+	user := d.Get("user").(string)
+	password := d.Get("password").(string)
+
+	if token := d.Get("api_token").(string); token != "" {
+		if user != "" || password != "" {
+			return nil, errors.New("api_token can't be used together with user and password")
+		}
+		// API tokens are sent as is in the auth field, no session is opened
+		api.Auth = token
+		return newProviderMeta(api)
+	}
+
+	if user == "" || password == "" {
+		return nil, errors.New("Either api_token or both user and password must be set")
 	}
 
-	if _, err := api.Login(d.Get("user").(string), d.Get("password").(string)); err != nil {
+	if _, err := api.Login(user, password); err != nil {
 		return nil, err
 	}
+	openedSessions.add(api)
 
-	return api, nil
+	return newProviderMeta(api)
 }
 
-func getZabbixServerVersion(meta interface{}) string {
-	api := meta.(*zabbix.API)
-	v, err := api.Version()
+// providerMeta is passed to the resources, it holds the API client and the
+// version of the server, which is fetched once when the provider is configured.
+type providerMeta struct {
+	api           *zabbix.API
+	zabbixVersion string
+}
+
+// newProviderMeta fetches the version of the server, the plan fails when it is
+// unknown rather than skipping the checks which depend on it.
+func newProviderMeta(api *zabbix.API) (*providerMeta, error) {
+	zabbixVersion, err := api.Version()
 	if err != nil {
-		log.Printf("[WARN] Failed to get Zabbix Server version: %v\n", err)
-		return ""
+		return nil, fmt.Errorf("Failed to get the Zabbix Server version: %s", err)
+	}
+	log.Printf("[DEBUG] Zabbix Server version is %s\n", zabbixVersion)
+
+	return &providerMeta{api: api, zabbixVersion: zabbixVersion}, nil
+}
+
+// sessions tracks the API clients logged in with user and password so they
+// can be logged out when the plugin exits.
+type sessions struct {
+	sync.Mutex
+	apis []*zabbix.API
+}
+
+var openedSessions sessions
+
+func (s *sessions) add(api *zabbix.API) {
+	s.Lock()
+	defer s.Unlock()
+	s.apis = append(s.apis, api)
+}
+
+// Logout closes every session opened by the provider with user.logout.
+// Clients authenticated with an API token are not tracked and left untouched.
+func Logout() {
+	openedSessions.Lock()
+	defer openedSessions.Unlock()
+
+	for _, api := range openedSessions.apis {
+		if _, err := api.CallWithError("user.logout", []string{}); err != nil {
+			log.Printf("[WARN] Failed to logout from Zabbix Server: %v\n", err)
+			continue
+		}
+		api.Auth = ""
 	}
-	log.Printf("[DEBUG] Zabbix Server version is %s\n", v)
+	openedSessions.apis = nil
+}
 
-	return v
+// getZabbixServerVersion returns the version of the server fetched when the
+// provider was configured.
+func getZabbixServerVersion(meta interface{}) string {
+	return meta.(*providerMeta).zabbixVersion
 }
 
 func isZabbixServerVersion34OrHigher(zabbixVersion string) bool {
 	return version.Compare(zabbixVersion, "3.4.0", ">=")
 }
 
+func isZabbixServerVersion40OrHigher(zabbixVersion string) bool {
+	return version.Compare(zabbixVersion, "4.0.0", ">=")
+}
+
+func isZabbixServerVersion42OrHigher(zabbixVersion string) bool {
+	return version.Compare(zabbixVersion, "4.2.0", ">=")
+}
+
+func isZabbixServerVersion44OrHigher(zabbixVersion string) bool {
+	return version.Compare(zabbixVersion, "4.4.0", ">=")
+}
+
+func isZabbixServerVersion50OrHigher(zabbixVersion string) bool {
+	return version.Compare(zabbixVersion, "5.0.0", ">=")
+}
+
+func isZabbixServerVersion52OrHigher(zabbixVersion string) bool {
+	return version.Compare(zabbixVersion, "5.2.0", ">=")
+}
+
+func isZabbixServerVersion54OrHigher(zabbixVersion string) bool {
+	return version.Compare(zabbixVersion, "5.4.0", ">=")
+}
+
+func isZabbixServerVersion60OrHigher(zabbixVersion string) bool {
+	return version.Compare(zabbixVersion, "6.0.0", ">=")
+}
+
+func isZabbixServerVersion62OrHigher(zabbixVersion string) bool {
+	return version.Compare(zabbixVersion, "6.2.0", ">=")
+}
+
 func getZabbixServerUnitDays(zabbixVersion string) string {
 	if isZabbixServerVersion34OrHigher(zabbixVersion) {
 		return "d"
@@ -32,11 +32,13 @@ func testAccPreCheck(t *testing.T) {
 	if v := os.Getenv("ZABBIX_SERVER_URL"); v == "" {
 		t.Fatal("ZABBIX_SERVER_URL must be set for acceptance tests")
 	}
-	if v := os.Getenv("ZABBIX_USER"); v == "" {
-		t.Fatal("ZABBIX_USER must be set for acceptance tests")
-	}
-	if v := os.Getenv("ZABBIX_PASSWORD"); v == "" {
-		t.Fatal("ZABBIX_PASSWORD must be set for acceptance tests")
+	if v := os.Getenv("ZABBIX_API_TOKEN"); v == "" {
+		if v := os.Getenv("ZABBIX_USER"); v == "" {
+			t.Fatal("ZABBIX_USER or ZABBIX_API_TOKEN must be set for acceptance tests")
+		}
+		if v := os.Getenv("ZABBIX_PASSWORD"); v == "" {
+			t.Fatal("ZABBIX_PASSWORD must be set for acceptance tests")
+		}
 	}
 
 	err := testAccProvider.Configure(terraform.NewResourceConfigRaw(nil))
@@ -44,3 +46,18 @@ func testAccPreCheck(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// testAccPreCheckZabbixVersion skips the tests of the features not supported
+// by the version of the server
+func testAccPreCheckZabbixVersion(t *testing.T, isSupported func(string) bool) {
+	testAccPreCheck(t)
+
+	zabbixVersion := getZabbixServerVersion(testAccProvider.Meta())
+	if !isSupported(zabbixVersion) {
+		t.Skipf("Not supported by Zabbix %s", zabbixVersion)
+	}
+}
+
+func isZabbixServerVersionBefore54(zabbixVersion string) bool {
+	return !isZabbixServerVersion54OrHigher(zabbixVersion)
+}
@@ -0,0 +1,999 @@
+package zabbix
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/claranet/go-zabbix-api"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+// ActionEventSources zabbix different action event source
+var ActionEventSources = map[string]int{
+	"trigger":          0,
+	"discovery":        1,
+	"autoregistration": 2,
+	"internal":         3,
+}
+
+func resourceZabbixAction() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceZabbixActionCreate,
+		Read:   resourceZabbixActionRead,
+		Exists: resourceZabbixActionExists,
+		Update: resourceZabbixActionUpdate,
+		Delete: resourceZabbixActionDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the action.",
+			},
+			"event_source": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "Type of events the action will handle: trigger, discovery, autoregistration or internal.",
+				ValidateFunc: validation.StringInSlice([]string{"trigger", "discovery", "autoregistration", "internal"}, false),
+			},
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"esc_period": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "Default operation step duration. Default: 3600 (1h for 3.4+).",
+			},
+			"pause_suppressed": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether to pause escalation during maintenance periods. Only used by trigger actions (Zabbix 4.0+).",
+			},
+			"notify_if_canceled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether to notify when escalation is canceled. Only used by trigger actions (Zabbix 5.0+).",
+			},
+			"default_subject": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Problem message subject (Removed in Zabbix 5.0).",
+			},
+			"default_message": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Problem message text (Removed in Zabbix 5.0).",
+			},
+			"recovery_subject": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Recovery message subject (Removed in Zabbix 5.0).",
+			},
+			"recovery_message": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Recovery message text (Removed in Zabbix 5.0).",
+			},
+			"update_subject": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Update message subject (Removed in Zabbix 5.0).",
+			},
+			"update_message": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Update message text (Removed in Zabbix 5.0).",
+			},
+			"filter": &schema.Schema{
+				Type:     schema.TypeList,
+				MaxItems: 1,
+				Elem:     schemaActionFilter(),
+				Optional: true,
+			},
+			"operation": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     schemaActionOperation(),
+				Optional: true,
+			},
+			"recovery_operation": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     schemaActionRecoveryOperation(),
+				Optional: true,
+			},
+			"update_operation": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     schemaActionRecoveryOperation(),
+				Optional: true,
+			},
+		},
+	}
+}
+
+func schemaActionFilter() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"condition": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     schemaActionFilterCondition(),
+				Required: true,
+			},
+			"eval_type": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "0 (and/or), 1 (and), 2 (or), 3 (custom expression).",
+				ValidateFunc: validation.IntBetween(0, 3),
+			},
+			"formula": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "Custom expression, required when eval_type is 3.",
+			},
+		},
+	}
+}
+
+func schemaActionFilterCondition() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"type": &schema.Schema{
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+			"operator": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+			},
+			"value": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"value2": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"formula_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "ID used to reference the condition in a custom expression.",
+			},
+		},
+	}
+}
+
+func schemaActionOperation() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"type": &schema.Schema{
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntBetween(0, 10),
+			},
+			"esc_period": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "0",
+				Description: "Duration of the escalation step, 0 uses the action default.",
+			},
+			"esc_step_from": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  1,
+			},
+			"esc_step_to": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     1,
+				Description: "Step to end escalation at, 0 for infinite.",
+			},
+			"eval_type": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Operation condition evaluation method: 0 (and/or), 1 (and), 2 (or).",
+				ValidateFunc: validation.IntBetween(0, 2),
+			},
+			"condition": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     schemaActionOperationCondition(),
+				Optional: true,
+			},
+			"message": &schema.Schema{
+				Type:     schema.TypeList,
+				MaxItems: 1,
+				Elem:     schemaActionOperationMessage(),
+				Optional: true,
+			},
+			"command": &schema.Schema{
+				Type:     schema.TypeList,
+				MaxItems: 1,
+				Elem:     schemaActionOperationCommand(),
+				Optional: true,
+			},
+			"host_group_ids": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "Host groups to add hosts to or remove hosts from.",
+			},
+			"template_ids": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "Templates to link hosts to or unlink hosts from.",
+			},
+			"inventory_mode": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Inventory mode set by the operation: 0 (manual), 1 (automatic).",
+				ValidateFunc: validation.IntBetween(0, 1),
+			},
+		},
+	}
+}
+
+func schemaActionRecoveryOperation() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"type": &schema.Schema{
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntInSlice([]int{0, 1, 11, 12}),
+			},
+			"message": &schema.Schema{
+				Type:     schema.TypeList,
+				MaxItems: 1,
+				Elem:     schemaActionOperationMessage(),
+				Optional: true,
+			},
+			"command": &schema.Schema{
+				Type:     schema.TypeList,
+				MaxItems: 1,
+				Elem:     schemaActionOperationCommand(),
+				Optional: true,
+			},
+		},
+	}
+}
+
+func schemaActionOperationCondition() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"operator": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntBetween(0, 1),
+			},
+			"value": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Event acknowledged: 0 (not acknowledged), 1 (acknowledged).",
+			},
+		},
+	}
+}
+
+func schemaActionOperationMessage() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"default_message": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether to use the default message of the action or media type.",
+			},
+			"media_type_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "0",
+				Description: "Media type used to send the message, 0 for all media types.",
+			},
+			"subject": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"message": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"user_group_ids": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+			},
+			"user_ids": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+			},
+		},
+	}
+}
+
+func schemaActionOperationCommand() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"type": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "0 (custom script), 1 (IPMI), 2 (SSH), 3 (Telnet), 4 (global script). Ignored on Zabbix 5.4+.",
+				ValidateFunc: validation.IntBetween(0, 4),
+			},
+			"script_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"command": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"execute_on": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "0 (agent), 1 (server), 2 (server or proxy).",
+				ValidateFunc: validation.IntBetween(0, 2),
+			},
+			"port": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"auth_type": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "SSH authentication method: 0 (password), 1 (public key).",
+				ValidateFunc: validation.IntBetween(0, 1),
+			},
+			"username": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"password": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Default:   "",
+				Sensitive: true,
+			},
+			"public_key": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"private_key": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"host_ids": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "Hosts to run the command on, 0 for the current host.",
+			},
+			"host_group_ids": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+			},
+		},
+	}
+}
+
+func resourceZabbixActionCreate(d *schema.ResourceData, meta interface{}) error {
+	action, err := createActionObj(d, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
+
+	return createRetry(d, meta, createAction, *action, resourceZabbixActionRead)
+}
+
+func resourceZabbixActionRead(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+	zabbixVersion := getZabbixServerVersion(meta)
+
+	params := zabbix.Params{
+		"actionids":                d.Id(),
+		"output":                   "extend",
+		"selectFilter":             "extend",
+		"selectOperations":         "extend",
+		"selectRecoveryOperations": "extend",
+	}
+	if isZabbixServerVersion52OrHigher(zabbixVersion) {
+		params["selectUpdateOperations"] = "extend"
+	} else {
+		params["selectAcknowledgeOperations"] = "extend"
+	}
+	actions, err := actionsGet(api, params)
+	if err != nil {
+		return err
+	}
+	if len(actions) != 1 {
+		return fmt.Errorf("Expected one action with id %s and got %d actions", d.Id(), len(actions))
+	}
+	action := actions[0]
+
+	for name, eventSource := range ActionEventSources {
+		if action.EventSource != nil && eventSource == *action.EventSource {
+			d.Set("event_source", name)
+		}
+	}
+	d.Set("name", action.Name)
+	d.Set("enabled", action.Status == 0)
+	d.Set("esc_period", action.EscPeriod)
+	if action.PauseSuppressed != "" {
+		d.Set("pause_suppressed", action.PauseSuppressed == "1")
+	}
+	if action.NotifyIfCanceled != "" {
+		d.Set("notify_if_canceled", action.NotifyIfCanceled == "1")
+	}
+	if !isZabbixServerVersion50OrHigher(zabbixVersion) {
+		d.Set("default_subject", action.DefaultSubject)
+		d.Set("default_message", action.DefaultMessage)
+		d.Set("recovery_subject", action.RecoverySubject)
+		d.Set("recovery_message", action.RecoveryMessage)
+		d.Set("update_subject", action.UpdateSubject)
+		d.Set("update_message", action.UpdateMessage)
+	}
+
+	if len(action.Filter.Conditions) > 0 {
+		d.Set("filter", flattenActionFilter(action.Filter))
+	} else {
+		d.Set("filter", nil)
+	}
+
+	operations := make([]interface{}, len(action.Operations))
+	for i, operation := range action.Operations {
+		operations[i] = flattenActionOperation(operation)
+	}
+	d.Set("operation", operations)
+
+	recoveryOperations := make([]interface{}, len(action.RecoveryOperations))
+	for i, operation := range action.RecoveryOperations {
+		recoveryOperations[i] = flattenActionRecoveryOperation(operation)
+	}
+	d.Set("recovery_operation", recoveryOperations)
+
+	updateOperations := action.UpdateOperations
+	if updateOperations == nil {
+		updateOperations = action.AcknowledgeOperations
+	}
+	terraformUpdateOperations := make([]interface{}, len(updateOperations))
+	for i, operation := range updateOperations {
+		terraformUpdateOperations[i] = flattenActionRecoveryOperation(operation)
+	}
+	d.Set("update_operation", terraformUpdateOperations)
+
+	log.Printf("[DEBUG] Action name is %s\n", action.Name)
+	return nil
+}
+
+func resourceZabbixActionExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	api := meta.(*providerMeta).api
+
+	_, err := actionGetByID(api, d.Id())
+	if err != nil {
+		if strings.Contains(err.Error(), "Expected exactly one result") {
+			log.Printf("[DEBUG] Action with id %s doesn't exist", d.Id())
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func resourceZabbixActionUpdate(d *schema.ResourceData, meta interface{}) error {
+	action, err := createActionObj(d, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
+
+	action.ActionID = d.Id()
+	// the event source of an action can't be updated
+	action.EventSource = nil
+	return createRetry(d, meta, updateAction, *action, resourceZabbixActionRead)
+}
+
+func resourceZabbixActionDelete(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	return callDeleteByIDs(api, "action.delete", []string{d.Id()}, "actionids")
+}
+
+func createActionObj(d *schema.ResourceData, zabbixVersion string) (*action, error) {
+	eventSource := ActionEventSources[d.Get("event_source").(string)]
+	action := action{
+		Name:        d.Get("name").(string),
+		EventSource: &eventSource,
+		Status:      0,
+		EscPeriod:   d.Get("esc_period").(string),
+		Filter:      createActionFilterObj(d),
+	}
+	if !d.Get("enabled").(bool) {
+		action.Status = 1
+	}
+
+	if eventSource == ActionEventSources["trigger"] {
+		if isZabbixServerVersion40OrHigher(zabbixVersion) {
+			action.PauseSuppressed = boolToString(d.Get("pause_suppressed").(bool))
+		}
+		if isZabbixServerVersion50OrHigher(zabbixVersion) {
+			action.NotifyIfCanceled = boolToString(d.Get("notify_if_canceled").(bool))
+		}
+	}
+
+	if isZabbixServerVersion50OrHigher(zabbixVersion) {
+		for _, key := range []string{"default_subject", "default_message", "recovery_subject", "recovery_message", "update_subject", "update_message"} {
+			if d.Get(key).(string) != "" {
+				return nil, fmt.Errorf("%s is not supported by Zabbix %s, use the message of the operations instead", key, zabbixVersion)
+			}
+		}
+	} else {
+		action.DefaultSubject = d.Get("default_subject").(string)
+		action.DefaultMessage = d.Get("default_message").(string)
+		action.RecoverySubject = d.Get("recovery_subject").(string)
+		action.RecoveryMessage = d.Get("recovery_message").(string)
+		action.UpdateSubject = d.Get("update_subject").(string)
+		action.UpdateMessage = d.Get("update_message").(string)
+	}
+
+	action.Operations = actionOperations{}
+	for _, terraformOperation := range d.Get("operation").(*schema.Set).List() {
+		action.Operations = append(action.Operations, createActionOperationObj(terraformOperation.(map[string]interface{}), zabbixVersion))
+	}
+
+	recoveryOperations := actionOperations{}
+	for _, terraformOperation := range d.Get("recovery_operation").(*schema.Set).List() {
+		recoveryOperations = append(recoveryOperations, createActionRecoveryOperationObj(terraformOperation.(map[string]interface{}), zabbixVersion))
+	}
+	updateOperations := actionOperations{}
+	for _, terraformOperation := range d.Get("update_operation").(*schema.Set).List() {
+		updateOperations = append(updateOperations, createActionRecoveryOperationObj(terraformOperation.(map[string]interface{}), zabbixVersion))
+	}
+
+	// recovery and update operations are sent only for trigger and internal actions
+	switch eventSource {
+	case ActionEventSources["trigger"]:
+		action.RecoveryOperations = recoveryOperations
+		if isZabbixServerVersion52OrHigher(zabbixVersion) {
+			action.UpdateOperations = updateOperations
+		} else if isZabbixServerVersion34OrHigher(zabbixVersion) {
+			action.AcknowledgeOperations = updateOperations
+		}
+	case ActionEventSources["internal"]:
+		action.RecoveryOperations = recoveryOperations
+	}
+	if action.RecoveryOperations == nil && len(recoveryOperations) > 0 {
+		return nil, fmt.Errorf("recovery_operation is only supported by trigger and internal actions")
+	}
+	if action.UpdateOperations == nil && action.AcknowledgeOperations == nil && len(updateOperations) > 0 {
+		return nil, fmt.Errorf("update_operation is only supported by trigger actions")
+	}
+
+	return &action, nil
+}
+
+func createActionFilterObj(d *schema.ResourceData) actionFilter {
+	filter := actionFilter{
+		Conditions: actionConditions{},
+	}
+
+	filters := d.Get("filter").([]interface{})
+	if len(filters) == 0 || filters[0] == nil {
+		return filter
+	}
+	terraformFilter := filters[0].(map[string]interface{})
+
+	filter.EvalType = terraformFilter["eval_type"].(int)
+	filter.Formula = terraformFilter["formula"].(string)
+	for _, terraformCondition := range terraformFilter["condition"].(*schema.Set).List() {
+		value := terraformCondition.(map[string]interface{})
+		filter.Conditions = append(filter.Conditions, actionCondition{
+			ConditionType: value["type"].(int),
+			Operator:      value["operator"].(int),
+			Value:         value["value"].(string),
+			Value2:        value["value2"].(string),
+			FormulaID:     value["formula_id"].(string),
+		})
+	}
+	return filter
+}
+
+func createActionMessageObj(operation *actionOperation, terraformOperation map[string]interface{}) {
+	messages := terraformOperation["message"].([]interface{})
+	if len(messages) == 0 || messages[0] == nil {
+		return
+	}
+	message := messages[0].(map[string]interface{})
+
+	operation.Message = &actionOperationMessage{
+		DefaultMessage: boolToString(message["default_message"].(bool)),
+		MediaTypeID:    message["media_type_id"].(string),
+		Subject:        message["subject"].(string),
+		Message:        message["message"].(string),
+	}
+	for _, id := range setToStringSlice(message["user_group_ids"].(*schema.Set)) {
+		operation.MessageUserGroups = append(operation.MessageUserGroups, actionOperationUserGroup{UserGroupID: id})
+	}
+	for _, id := range setToStringSlice(message["user_ids"].(*schema.Set)) {
+		operation.MessageUsers = append(operation.MessageUsers, actionOperationUser{UserID: id})
+	}
+}
+
+func createActionCommandObj(operation *actionOperation, terraformOperation map[string]interface{}, zabbixVersion string) {
+	commands := terraformOperation["command"].([]interface{})
+	if len(commands) == 0 || commands[0] == nil {
+		return
+	}
+	command := commands[0].(map[string]interface{})
+
+	if isZabbixServerVersion54OrHigher(zabbixVersion) {
+		// Only global scripts can be run from Zabbix 5.4
+		operation.Command = &actionOperationCommand{
+			ScriptID: command["script_id"].(string),
+		}
+	} else {
+		operation.Command = &actionOperationCommand{
+			Type:       strconv.Itoa(command["type"].(int)),
+			ScriptID:   command["script_id"].(string),
+			Command:    command["command"].(string),
+			ExecuteOn:  strconv.Itoa(command["execute_on"].(int)),
+			Port:       command["port"].(string),
+			AuthType:   strconv.Itoa(command["auth_type"].(int)),
+			Username:   command["username"].(string),
+			Password:   command["password"].(string),
+			PublicKey:  command["public_key"].(string),
+			PrivateKey: command["private_key"].(string),
+		}
+	}
+	for _, id := range setToStringSlice(command["host_ids"].(*schema.Set)) {
+		operation.CommandHosts = append(operation.CommandHosts, actionOperationCommandHost{HostID: id})
+	}
+	for _, id := range setToStringSlice(command["host_group_ids"].(*schema.Set)) {
+		operation.CommandHostGroups = append(operation.CommandHostGroups, actionOperationCommandHostGroup{GroupID: id})
+	}
+}
+
+func createActionOperationObj(terraformOperation map[string]interface{}, zabbixVersion string) actionOperation {
+	operation := actionOperation{
+		OperationType: terraformOperation["type"].(int),
+		EscPeriod:     terraformOperation["esc_period"].(string),
+		EscStepFrom:   strconv.Itoa(terraformOperation["esc_step_from"].(int)),
+		EscStepTo:     strconv.Itoa(terraformOperation["esc_step_to"].(int)),
+		EvalType:      strconv.Itoa(terraformOperation["eval_type"].(int)),
+	}
+
+	for _, terraformCondition := range terraformOperation["condition"].(*schema.Set).List() {
+		value := terraformCondition.(map[string]interface{})
+		operation.Conditions = append(operation.Conditions, actionOperationCondition{
+			ConditionType: "14",
+			Operator:      strconv.Itoa(value["operator"].(int)),
+			Value:         value["value"].(string),
+		})
+	}
+
+	createActionMessageObj(&operation, terraformOperation)
+	createActionCommandObj(&operation, terraformOperation, zabbixVersion)
+
+	for _, id := range setToStringSlice(terraformOperation["host_group_ids"].(*schema.Set)) {
+		operation.HostGroups = append(operation.HostGroups, zabbix.HostGroupID{GroupID: id})
+	}
+	for _, id := range setToStringSlice(terraformOperation["template_ids"].(*schema.Set)) {
+		operation.Templates = append(operation.Templates, zabbix.TemplateID{TemplateID: id})
+	}
+	// set inventory mode
+	if operation.OperationType == 10 {
+		operation.Inventory = &actionOperationInventory{
+			InventoryMode: strconv.Itoa(terraformOperation["inventory_mode"].(int)),
+		}
+	}
+	return operation
+}
+
+func createActionRecoveryOperationObj(terraformOperation map[string]interface{}, zabbixVersion string) actionOperation {
+	operation := actionOperation{
+		OperationType: terraformOperation["type"].(int),
+	}
+
+	createActionMessageObj(&operation, terraformOperation)
+	createActionCommandObj(&operation, terraformOperation, zabbixVersion)
+	return operation
+}
+
+func flattenActionFilter(filter actionFilter) []interface{} {
+	conditions := make([]interface{}, len(filter.Conditions))
+	for i, condition := range filter.Conditions {
+		terraformCondition := map[string]interface{}{
+			"type":       condition.ConditionType,
+			"operator":   condition.Operator,
+			"value":      condition.Value,
+			"value2":     condition.Value2,
+			"formula_id": "",
+		}
+		// formula ids are generated by the server unless a custom expression is used
+		if filter.EvalType == 3 {
+			terraformCondition["formula_id"] = condition.FormulaID
+		}
+		conditions[i] = terraformCondition
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"condition": conditions,
+			"eval_type": filter.EvalType,
+			"formula":   filter.Formula,
+		},
+	}
+}
+
+func flattenActionMessage(operation actionOperation) []interface{} {
+	if operation.Message == nil {
+		return nil
+	}
+
+	userGroupIDs := make([]string, len(operation.MessageUserGroups))
+	for i, userGroup := range operation.MessageUserGroups {
+		userGroupIDs[i] = userGroup.UserGroupID
+	}
+	userIDs := make([]string, len(operation.MessageUsers))
+	for i, user := range operation.MessageUsers {
+		userIDs[i] = user.UserID
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"default_message": operation.Message.DefaultMessage == "1",
+			"media_type_id":   operation.Message.MediaTypeID,
+			"subject":         operation.Message.Subject,
+			"message":         operation.Message.Message,
+			"user_group_ids":  schema.NewSet(schema.HashString, stringSliceToInterface(userGroupIDs)),
+			"user_ids":        schema.NewSet(schema.HashString, stringSliceToInterface(userIDs)),
+		},
+	}
+}
+
+func flattenActionCommand(operation actionOperation) []interface{} {
+	if operation.Command == nil {
+		return nil
+	}
+
+	hostIDs := make([]string, len(operation.CommandHosts))
+	for i, host := range operation.CommandHosts {
+		hostIDs[i] = host.HostID
+	}
+	hostGroupIDs := make([]string, len(operation.CommandHostGroups))
+	for i, group := range operation.CommandHostGroups {
+		hostGroupIDs[i] = group.GroupID
+	}
+
+	command := operation.Command
+	return []interface{}{
+		map[string]interface{}{
+			"type":           atoi(command.Type),
+			"script_id":      command.ScriptID,
+			"command":        command.Command,
+			"execute_on":     atoi(command.ExecuteOn),
+			"port":           command.Port,
+			"auth_type":      atoi(command.AuthType),
+			"username":       command.Username,
+			"password":       command.Password,
+			"public_key":     command.PublicKey,
+			"private_key":    command.PrivateKey,
+			"host_ids":       schema.NewSet(schema.HashString, stringSliceToInterface(hostIDs)),
+			"host_group_ids": schema.NewSet(schema.HashString, stringSliceToInterface(hostGroupIDs)),
+		},
+	}
+}
+
+func flattenActionOperation(operation actionOperation) map[string]interface{} {
+	conditions := make([]interface{}, len(operation.Conditions))
+	for i, condition := range operation.Conditions {
+		conditions[i] = map[string]interface{}{
+			"operator": atoi(condition.Operator),
+			"value":    condition.Value,
+		}
+	}
+	hostGroupIDs := make([]string, len(operation.HostGroups))
+	for i, group := range operation.HostGroups {
+		hostGroupIDs[i] = group.GroupID
+	}
+	templateIDs := make([]string, len(operation.Templates))
+	for i, template := range operation.Templates {
+		templateIDs[i] = template.TemplateID
+	}
+	inventoryMode := 0
+	if operation.Inventory != nil {
+		inventoryMode = atoi(operation.Inventory.InventoryMode)
+	}
+
+	return map[string]interface{}{
+		"type":           operation.OperationType,
+		"esc_period":     operation.EscPeriod,
+		"esc_step_from":  atoi(operation.EscStepFrom),
+		"esc_step_to":    atoi(operation.EscStepTo),
+		"eval_type":      atoi(operation.EvalType),
+		"condition":      conditions,
+		"message":        flattenActionMessage(operation),
+		"command":        flattenActionCommand(operation),
+		"host_group_ids": schema.NewSet(schema.HashString, stringSliceToInterface(hostGroupIDs)),
+		"template_ids":   schema.NewSet(schema.HashString, stringSliceToInterface(templateIDs)),
+		"inventory_mode": inventoryMode,
+	}
+}
+
+func flattenActionRecoveryOperation(operation actionOperation) map[string]interface{} {
+	return map[string]interface{}{
+		"type":    operation.OperationType,
+		"message": flattenActionMessage(operation),
+		"command": flattenActionCommand(operation),
+	}
+}
+
+func createAction(obj interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "action.create", []action{obj.(action)}, "actionids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+func updateAction(obj interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "action.update", []action{obj.(action)}, "actionids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+// action represent Zabbix action object
+// https://www.zabbix.com/documentation/current/manual/api/reference/action/object
+type action struct {
+	ActionID         string       `json:"actionid,omitempty"`
+	Name             string       `json:"name"`
+	EventSource      *int         `json:"eventsource,omitempty,string"`
+	Status           int          `json:"status,string"`
+	EscPeriod        string       `json:"esc_period,omitempty"`
+	PauseSuppressed  string       `json:"pause_suppressed,omitempty"`
+	NotifyIfCanceled string       `json:"notify_if_canceled,omitempty"`
+	DefaultSubject   string       `json:"def_shortdata,omitempty"`
+	DefaultMessage   string       `json:"def_longdata,omitempty"`
+	RecoverySubject  string       `json:"r_shortdata,omitempty"`
+	RecoveryMessage  string       `json:"r_longdata,omitempty"`
+	UpdateSubject    string       `json:"ack_shortdata,omitempty"`
+	UpdateMessage    string       `json:"ack_longdata,omitempty"`
+	Filter           actionFilter `json:"filter"`
+
+	Operations            actionOperations `json:"operations,omitempty"`
+	RecoveryOperations    actionOperations `json:"recovery_operations,omitempty"`
+	UpdateOperations      actionOperations `json:"update_operations,omitempty"`
+	AcknowledgeOperations actionOperations `json:"acknowledge_operations,omitempty"`
+}
+
+type actionFilter struct {
+	Conditions actionConditions `json:"conditions"`
+	EvalType   int              `json:"evaltype,string"`
+	Formula    string           `json:"formula,omitempty"`
+}
+
+type actionCondition struct {
+	ConditionType int    `json:"conditiontype,string"`
+	Operator      int    `json:"operator,string"`
+	Value         string `json:"value"`
+	Value2        string `json:"value2,omitempty"`
+	FormulaID     string `json:"formulaid,omitempty"`
+}
+
+type actionConditions []actionCondition
+
+type actionOperation struct {
+	OperationType     int                               `json:"operationtype,string"`
+	EscPeriod         string                            `json:"esc_period,omitempty"`
+	EscStepFrom       string                            `json:"esc_step_from,omitempty"`
+	EscStepTo         string                            `json:"esc_step_to,omitempty"`
+	EvalType          string                            `json:"evaltype,omitempty"`
+	Conditions        []actionOperationCondition        `json:"opconditions,omitempty"`
+	Message           *actionOperationMessage           `json:"opmessage,omitempty"`
+	MessageUserGroups []actionOperationUserGroup        `json:"opmessage_grp,omitempty"`
+	MessageUsers      []actionOperationUser             `json:"opmessage_usr,omitempty"`
+	Command           *actionOperationCommand           `json:"opcommand,omitempty"`
+	CommandHosts      []actionOperationCommandHost      `json:"opcommand_hst,omitempty"`
+	CommandHostGroups []actionOperationCommandHostGroup `json:"opcommand_grp,omitempty"`
+	HostGroups        zabbix.HostGroupIDs               `json:"opgroup,omitempty"`
+	Templates         zabbix.TemplateIDs                `json:"optemplate,omitempty"`
+	Inventory         *actionOperationInventory         `json:"opinventory,omitempty"`
+}
+
+type actionOperations []actionOperation
+
+type actionOperationCondition struct {
+	ConditionType string `json:"conditiontype"`
+	Operator      string `json:"operator"`
+	Value         string `json:"value"`
+}
+
+type actionOperationMessage struct {
+	DefaultMessage string `json:"default_msg"`
+	MediaTypeID    string `json:"mediatypeid"`
+	Subject        string `json:"subject"`
+	Message        string `json:"message"`
+}
+
+type actionOperationUserGroup struct {
+	UserGroupID string `json:"usrgrpid"`
+}
+
+type actionOperationUser struct {
+	UserID string `json:"userid"`
+}
+
+type actionOperationCommand struct {
+	Type       string `json:"type,omitempty"`
+	ScriptID   string `json:"scriptid,omitempty"`
+	Command    string `json:"command,omitempty"`
+	ExecuteOn  string `json:"execute_on,omitempty"`
+	Port       string `json:"port,omitempty"`
+	AuthType   string `json:"authtype,omitempty"`
+	Username   string `json:"username,omitempty"`
+	Password   string `json:"password,omitempty"`
+	PublicKey  string `json:"publickey,omitempty"`
+	PrivateKey string `json:"privatekey,omitempty"`
+}
+
+type actionOperationCommandHost struct {
+	HostID string `json:"hostid"`
+}
+
+type actionOperationCommandHostGroup struct {
+	GroupID string `json:"groupid"`
+}
+
+type actionOperationInventory struct {
+	InventoryMode string `json:"inventory_mode"`
+}
+
+func actionsGet(api *zabbix.API, params zabbix.Params) (res []action, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("action.get", params, &res)
+	return
+}
+
+func actionGetByID(api *zabbix.API, id string) (*action, error) {
+	actions, err := actionsGet(api, zabbix.Params{"actionids": id})
+	if err != nil {
+		return nil, err
+	}
+	if len(actions) != 1 {
+		return nil, expectOneResult(len(actions))
+	}
+	return &actions[0], nil
+}
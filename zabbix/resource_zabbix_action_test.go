@@ -0,0 +1,177 @@
+package zabbix
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccZabbixAction_Basic(t *testing.T) {
+	strID := acctest.RandString(5)
+	actionName := fmt.Sprintf("action_%s", strID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixActionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixActionConfig(strID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixActionExists("zabbix_action.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_action.zabbix", "name", actionName),
+					resource.TestCheckResourceAttr("zabbix_action.zabbix", "event_source", "trigger"),
+					resource.TestCheckResourceAttr("zabbix_action.zabbix", "enabled", "true"),
+					resource.TestCheckResourceAttr("zabbix_action.zabbix", "filter.0.condition.#", "2"),
+					resource.TestCheckResourceAttr("zabbix_action.zabbix", "operation.#", "2"),
+					resource.TestCheckResourceAttr("zabbix_action.zabbix", "recovery_operation.#", "1"),
+				),
+			},
+			{
+				Config: testAccZabbixActionUpdateConfig(strID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixActionExists("zabbix_action.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_action.zabbix", "name", fmt.Sprintf("update_%s", actionName)),
+					resource.TestCheckResourceAttr("zabbix_action.zabbix", "enabled", "false"),
+					resource.TestCheckResourceAttr("zabbix_action.zabbix", "filter.0.eval_type", "3"),
+					resource.TestCheckResourceAttr("zabbix_action.zabbix", "filter.0.formula", "A and B"),
+					resource.TestCheckResourceAttr("zabbix_action.zabbix", "operation.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_action.zabbix", "recovery_operation.#", "0"),
+				),
+			},
+			{
+				ResourceName:      "zabbix_action.zabbix",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckZabbixActionDestroy(s *terraform.State) error {
+	api := testAccProvider.Meta().(*providerMeta).api
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "zabbix_action" {
+			continue
+		}
+
+		_, err := actionGetByID(api, rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Action still exists")
+		}
+		expectedError := "Expected exactly one result, got 0."
+		if err.Error() != expectedError {
+			return fmt.Errorf("expected error : %s, got : %s", expectedError, err.Error())
+		}
+	}
+	return nil
+}
+
+func testAccCheckZabbixActionExists(resource string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resource)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No record ID set")
+		}
+
+		api := testAccProvider.Meta().(*providerMeta).api
+		_, err := actionGetByID(api, rs.Primary.ID)
+		return err
+	}
+}
+
+func testAccZabbixActionConfig(strID string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "zabbix" {
+			name = "host_group_%s"
+		}
+
+		resource "zabbix_action" "zabbix" {
+			name         = "action_%s"
+			event_source = "trigger"
+
+			filter {
+				condition {
+					type     = 0
+					value    = zabbix_host_group.zabbix.id
+				}
+				condition {
+					type     = 4
+					operator = 5
+					value    = "4"
+				}
+			}
+
+			operation {
+				type = 0
+				message {
+					user_ids = ["1"]
+				}
+			}
+
+			operation {
+				type          = 0
+				esc_step_from = 2
+				esc_step_to   = 0
+				esc_period    = "30m"
+				message {
+					default_message = false
+					subject         = "Problem: {EVENT.NAME}"
+					message         = "Problem started at {EVENT.TIME}"
+					user_ids        = ["1"]
+				}
+			}
+
+			recovery_operation {
+				type = 0
+				message {
+					user_ids = ["1"]
+				}
+			}
+		}
+	`, strID, strID)
+}
+
+func testAccZabbixActionUpdateConfig(strID string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "zabbix" {
+			name = "host_group_%s"
+		}
+
+		resource "zabbix_action" "zabbix" {
+			name         = "update_action_%s"
+			event_source = "trigger"
+			enabled      = false
+
+			filter {
+				eval_type = 3
+				formula   = "A and B"
+				condition {
+					type       = 0
+					value      = zabbix_host_group.zabbix.id
+					formula_id = "A"
+				}
+				condition {
+					type       = 4
+					operator   = 5
+					value      = "2"
+					formula_id = "B"
+				}
+			}
+
+			operation {
+				type = 0
+				message {
+					user_ids = ["1"]
+				}
+			}
+		}
+	`, strID, strID)
+}
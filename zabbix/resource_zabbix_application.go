@@ -0,0 +1,120 @@
+package zabbix
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/claranet/go-zabbix-api"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func resourceZabbixApplication() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceZabbixApplicationCreate,
+		Read:   resourceZabbixApplicationRead,
+		Exists: resourceZabbixApplicationExists,
+		Update: resourceZabbixApplicationUpdate,
+		Delete: resourceZabbixApplicationDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		CustomizeDiff: resourceZabbixApplicationCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the application.",
+			},
+			"host_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the host or template that the application belongs to.",
+			},
+		},
+	}
+}
+
+// resourceZabbixApplicationCustomizeDiff refuses applications on Zabbix 5.4+,
+// which replaced them with item tags.
+func resourceZabbixApplicationCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	zabbixVersion := getZabbixServerVersion(meta)
+	if isZabbixServerVersion54OrHigher(zabbixVersion) {
+		return fmt.Errorf("Applications are not supported from Zabbix 5.4, got %s, use the tags of the items instead", zabbixVersion)
+	}
+	return nil
+}
+
+func resourceZabbixApplicationCreate(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	applications := zabbix.Applications{
+		zabbix.Application{
+			Name:   d.Get("name").(string),
+			HostID: d.Get("host_id").(string),
+		},
+	}
+
+	err := api.ApplicationsCreate(applications)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Created application, id is %s", applications[0].ApplicationID)
+
+	d.SetId(applications[0].ApplicationID)
+	return resourceZabbixApplicationRead(d, meta)
+}
+
+func resourceZabbixApplicationRead(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	log.Printf("[DEBUG] Will read application with id %s", d.Id())
+
+	application, err := api.ApplicationGetByID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	d.Set("name", application.Name)
+	d.Set("host_id", application.HostID)
+
+	return nil
+}
+
+func resourceZabbixApplicationExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	api := meta.(*providerMeta).api
+
+	_, err := api.ApplicationGetByID(d.Id())
+	if err != nil {
+		if strings.Contains(err.Error(), "Expected exactly one result") {
+			log.Printf("[DEBUG] Application with id %s doesn't exist", d.Id())
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func resourceZabbixApplicationUpdate(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	_, err := callCreate(api, "application.update", []map[string]string{
+		{
+			"applicationid": d.Id(),
+			"name":          d.Get("name").(string),
+		},
+	}, "applicationids")
+	if err != nil {
+		return err
+	}
+
+	return resourceZabbixApplicationRead(d, meta)
+}
+
+func resourceZabbixApplicationDelete(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	return api.ApplicationsDeleteByIds([]string{d.Id()})
+}
@@ -0,0 +1,79 @@
+package zabbix
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccZabbixApplication_Basic(t *testing.T) {
+	strID := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckZabbixVersion(t, isZabbixServerVersionBefore54) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixApplicationDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixApplicationConfig(strID, "application"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_application.application_test", "name", "application"),
+					resource.TestCheckResourceAttrPair("zabbix_application.application_test", "host_id", "zabbix_template.template_test", "id"),
+				),
+			},
+			{
+				Config: testAccZabbixApplicationConfig(strID, "updated application"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_application.application_test", "name", "updated application"),
+				),
+			},
+			{
+				ResourceName:      "zabbix_application.application_test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckZabbixApplicationDestroy(s *terraform.State) error {
+	api := testAccProvider.Meta().(*providerMeta).api
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "zabbix_application" {
+			continue
+		}
+
+		_, err := api.ApplicationGetByID(rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Application still exists %s", rs.Primary.ID)
+		}
+
+		expectedError := "Expected exactly one result, got 0."
+		if err.Error() != expectedError {
+			return fmt.Errorf("expected error : %s, got : %s", expectedError, err.Error())
+		}
+	}
+	return nil
+}
+
+func testAccZabbixApplicationConfig(strID string, name string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "host_group_test" {
+			name = "host_group_%s"
+		}
+
+		resource "zabbix_template" "template_test" {
+			host = "template_%s"
+			groups = [zabbix_host_group.host_group_test.name]
+		}
+
+		resource "zabbix_application" "application_test" {
+			name = "%s"
+			host_id = zabbix_template.template_test.id
+		}
+	`, strID, strID, name)
+}
@@ -0,0 +1,180 @@
+package zabbix
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/claranet/go-zabbix-api"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+func resourceZabbixGlobalMacro() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceZabbixGlobalMacroCreate,
+		Read:   resourceZabbixGlobalMacroRead,
+		Exists: resourceZabbixGlobalMacroExists,
+		Update: resourceZabbixGlobalMacroUpdate,
+		Delete: resourceZabbixGlobalMacroDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceZabbixGlobalMacroImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the macro, with or without {$ }.",
+			},
+			"value": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Value of the macro, the values of secret macros are never read back from Zabbix.",
+			},
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "text",
+				ValidateFunc: validation.StringInSlice([]string{"text", "secret", "vault"}, false),
+			},
+		},
+	}
+}
+
+func resourceZabbixGlobalMacroCreate(d *schema.ResourceData, meta interface{}) error {
+	macro, err := createGlobalMacroObj(d, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
+
+	return createRetry(d, meta, createGlobalMacro, *macro, resourceZabbixGlobalMacroRead)
+}
+
+func resourceZabbixGlobalMacroRead(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	macro, err := globalMacroGetByID(api, d.Id())
+	if err != nil {
+		return err
+	}
+
+	stateMacro := map[string]interface{}{
+		"name":  d.Get("name"),
+		"value": d.Get("value"),
+	}
+	terraformMacro := readUserMacro(*macro, []interface{}{stateMacro})
+	for key, value := range terraformMacro {
+		d.Set(key, value)
+	}
+
+	log.Printf("[DEBUG] Global macro name is %s\n", macro.Macro)
+	return nil
+}
+
+func resourceZabbixGlobalMacroExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	api := meta.(*providerMeta).api
+
+	_, err := globalMacroGetByID(api, d.Id())
+	if err != nil {
+		if strings.Contains(err.Error(), "Expected exactly one result") {
+			log.Printf("[DEBUG] Global macro with id %s doesn't exist", d.Id())
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func resourceZabbixGlobalMacroUpdate(d *schema.ResourceData, meta interface{}) error {
+	macro, err := createGlobalMacroObj(d, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
+
+	macro.GlobalMacroID = d.Id()
+	return createRetry(d, meta, updateGlobalMacro, *macro, resourceZabbixGlobalMacroRead)
+}
+
+func resourceZabbixGlobalMacroDelete(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	return callDeleteByIDs(api, "usermacro.deleteglobal", []string{d.Id()}, "globalmacroids")
+}
+
+// resourceZabbixGlobalMacroImport accepts the id of the macro or its name,
+// with or without {$ }.
+func resourceZabbixGlobalMacroImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	api := meta.(*providerMeta).api
+
+	if _, err := strconv.Atoi(d.Id()); err == nil {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	name := normalizeMacroName(d.Id())
+	macros, err := globalMacrosGet(api, zabbix.Params{
+		"filter": map[string]interface{}{
+			"macro": name,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(macros) != 1 {
+		return nil, fmt.Errorf("Expected one global macro with name %s and got %d macros", name, len(macros))
+	}
+	d.SetId(macros[0].GlobalMacroID)
+	return []*schema.ResourceData{d}, nil
+}
+
+func createGlobalMacroObj(d *schema.ResourceData, zabbixVersion string) (*userMacro, error) {
+	return createUserMacro(map[string]interface{}{
+		"name":        d.Get("name"),
+		"value":       d.Get("value"),
+		"description": d.Get("description"),
+		"type":        d.Get("type"),
+	}, zabbixVersion)
+}
+
+func createGlobalMacro(obj interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "usermacro.createglobal", []userMacro{obj.(userMacro)}, "globalmacroids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+func updateGlobalMacro(obj interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "usermacro.updateglobal", []userMacro{obj.(userMacro)}, "globalmacroids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+func globalMacrosGet(api *zabbix.API, params zabbix.Params) (res []userMacro, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	params["globalmacro"] = true
+	err = api.CallWithErrorParse("usermacro.get", params, &res)
+	return
+}
+
+func globalMacroGetByID(api *zabbix.API, id string) (*userMacro, error) {
+	macros, err := globalMacrosGet(api, zabbix.Params{"globalmacroids": id})
+	if err != nil {
+		return nil, err
+	}
+	if len(macros) != 1 {
+		return nil, expectOneResult(len(macros))
+	}
+	return &macros[0], nil
+}
@@ -0,0 +1,94 @@
+package zabbix
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccZabbixGlobalMacro_Basic(t *testing.T) {
+	strID := strings.ToUpper(acctest.RandString(5))
+	macroName := fmt.Sprintf("{$GLOBAL_%s}", strID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixGlobalMacroDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixGlobalMacroConfig(fmt.Sprintf("GLOBAL_%s", strID), "85"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixGlobalMacroExists("zabbix_global_macro.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_global_macro.zabbix", "name", fmt.Sprintf("GLOBAL_%s", strID)),
+					resource.TestCheckResourceAttr("zabbix_global_macro.zabbix", "value", "85"),
+					resource.TestCheckResourceAttr("zabbix_global_macro.zabbix", "type", "text"),
+				),
+			},
+			{
+				Config: testAccZabbixGlobalMacroConfig(macroName, "90"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixGlobalMacroExists("zabbix_global_macro.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_global_macro.zabbix", "name", macroName),
+					resource.TestCheckResourceAttr("zabbix_global_macro.zabbix", "value", "90"),
+				),
+			},
+			{
+				ResourceName:      "zabbix_global_macro.zabbix",
+				ImportState:       true,
+				ImportStateId:     fmt.Sprintf("GLOBAL_%s", strID),
+				ImportStateVerify: true,
+				// The name is read in the form of the state, which is empty on import
+				ImportStateVerifyIgnore: []string{"name"},
+			},
+		},
+	})
+}
+
+func testAccCheckZabbixGlobalMacroDestroy(s *terraform.State) error {
+	api := testAccProvider.Meta().(*providerMeta).api
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "zabbix_global_macro" {
+			continue
+		}
+
+		_, err := globalMacroGetByID(api, rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Global macro still exists")
+		}
+		expectedError := "Expected exactly one result, got 0."
+		if err.Error() != expectedError {
+			return fmt.Errorf("expected error : %s, got : %s", expectedError, err.Error())
+		}
+	}
+	return nil
+}
+
+func testAccCheckZabbixGlobalMacroExists(resource string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resource)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No record ID set")
+		}
+
+		api := testAccProvider.Meta().(*providerMeta).api
+		_, err := globalMacroGetByID(api, rs.Primary.ID)
+		return err
+	}
+}
+
+func testAccZabbixGlobalMacroConfig(name string, value string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_global_macro" "zabbix" {
+			name  = "%s"
+			value = "%s"
+		}
+	`, name, value)
+}
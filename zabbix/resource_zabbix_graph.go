@@ -0,0 +1,456 @@
+package zabbix
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/claranet/go-zabbix-api"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+// GraphTypes zabbix different types of graphs
+var GraphTypes = map[string]int{
+	"normal":   0,
+	"stacked":  1,
+	"pie":      2,
+	"exploded": 3,
+}
+
+// GraphYAxisTypes zabbix different ways to compute the minimum and maximum
+// values of the y axis
+var GraphYAxisTypes = map[string]int{
+	"calculated": 0,
+	"fixed":      1,
+	"item":       2,
+}
+
+// GraphItemDrawTypes zabbix different draw styles of graph items
+var GraphItemDrawTypes = map[string]int{
+	"line":          0,
+	"filled_region": 1,
+	"bold_line":     2,
+	"dot":           3,
+	"dashed_line":   4,
+	"gradient_line": 5,
+}
+
+// GraphItemYAxisSides zabbix different sides of the y axis of graph items
+var GraphItemYAxisSides = map[string]int{
+	"left":  0,
+	"right": 1,
+}
+
+// GraphItemCalcFunctions zabbix different values drawn for graph items
+var GraphItemCalcFunctions = map[string]int{
+	"min":     1,
+	"average": 2,
+	"max":     4,
+	"all":     7,
+	"last":    9,
+}
+
+// GraphItemTypes zabbix different types of graph items
+var GraphItemTypes = map[string]int{
+	"simple": 0,
+	"sum":    2,
+}
+
+var graphItemColorRegexp = regexp.MustCompile(`^[0-9A-Fa-f]{6}$`)
+
+func resourceZabbixGraph() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceZabbixGraphCreate,
+		Read:   resourceZabbixGraphRead,
+		Exists: resourceZabbixGraphExists,
+		Update: resourceZabbixGraphUpdate,
+		Delete: resourceZabbixGraphDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: schemaGraph("ID of the item drawn, of the host or template of the graph."),
+	}
+}
+
+// schemaGraph returns the arguments shared by graphs and graph prototypes.
+func schemaGraph(itemIDDescription string) map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": &schema.Schema{
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Name of the graph.",
+		},
+		"width": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      900,
+			ValidateFunc: validation.IntBetween(20, 65535),
+		},
+		"height": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      200,
+			ValidateFunc: validation.IntBetween(20, 65535),
+		},
+		"type": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "normal",
+			ValidateFunc: validation.StringInSlice([]string{"normal", "stacked", "pie", "exploded"}, false),
+		},
+		"show_legend": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
+		"show_work_period": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Show the working time on normal and stacked graphs.",
+		},
+		"show_triggers": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Show the thresholds of the simple triggers on normal and stacked graphs.",
+		},
+		"show_3d": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Draw pie and exploded graphs in 3D.",
+		},
+		"percent_left": &schema.Schema{
+			Type:         schema.TypeFloat,
+			Optional:     true,
+			Default:      0,
+			Description:  "Percentile line of the left y axis of normal graphs, 0 to hide it.",
+			ValidateFunc: validation.FloatBetween(0, 100),
+		},
+		"percent_right": &schema.Schema{
+			Type:         schema.TypeFloat,
+			Optional:     true,
+			Default:      0,
+			Description:  "Percentile line of the right y axis of normal graphs, 0 to hide it.",
+			ValidateFunc: validation.FloatBetween(0, 100),
+		},
+		"ymin_type": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "calculated",
+			Description:  "How the minimum value of the y axis is computed.",
+			ValidateFunc: validation.StringInSlice([]string{"calculated", "fixed", "item"}, false),
+		},
+		"ymin": &schema.Schema{
+			Type:        schema.TypeFloat,
+			Optional:    true,
+			Default:     0,
+			Description: "Minimum value of the y axis, used by the fixed ymin_type.",
+		},
+		"ymin_item_id": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "ID of the item of the minimum value of the y axis, used by the item ymin_type.",
+		},
+		"ymax_type": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "calculated",
+			Description:  "How the maximum value of the y axis is computed.",
+			ValidateFunc: validation.StringInSlice([]string{"calculated", "fixed", "item"}, false),
+		},
+		"ymax": &schema.Schema{
+			Type:        schema.TypeFloat,
+			Optional:    true,
+			Default:     100,
+			Description: "Maximum value of the y axis, used by the fixed ymax_type.",
+		},
+		"ymax_item_id": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "ID of the item of the maximum value of the y axis, used by the item ymax_type.",
+		},
+		"graph_item": &schema.Schema{
+			Type:        schema.TypeList,
+			Elem:        schemaGraphItem(itemIDDescription),
+			Required:    true,
+			MinItems:    1,
+			Description: "Ordered items drawn by the graph.",
+		},
+	}
+}
+
+func schemaGraphItem(itemIDDescription string) *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"item_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: itemIDDescription,
+			},
+			"color": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Hexadecimal RGB color of the item, e.g. 00AA00.",
+				ValidateFunc: validation.StringMatch(graphItemColorRegexp, "must be an hexadecimal RGB color like 00AA00"),
+				StateFunc: func(v interface{}) string {
+					return strings.ToUpper(v.(string))
+				},
+			},
+			"draw_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "line",
+				ValidateFunc: validation.StringInSlice([]string{"line", "filled_region", "bold_line", "dot", "dashed_line", "gradient_line"}, false),
+			},
+			"yaxis_side": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "left",
+				ValidateFunc: validation.StringInSlice([]string{"left", "right"}, false),
+			},
+			"calc_function": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "average",
+				Description:  "Value drawn when several values are aggregated.",
+				ValidateFunc: validation.StringInSlice([]string{"min", "average", "max", "all", "last"}, false),
+			},
+			"type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "simple",
+				Description:  "Graph sum items of pie and exploded graphs draw the whole pie.",
+				ValidateFunc: validation.StringInSlice([]string{"simple", "sum"}, false),
+			},
+		},
+	}
+}
+
+func resourceZabbixGraphCreate(d *schema.ResourceData, meta interface{}) error {
+	graph, err := createGraphObject(d)
+	if err != nil {
+		return err
+	}
+
+	return createRetry(d, meta, createGraph, *graph, resourceZabbixGraphRead)
+}
+
+func resourceZabbixGraphRead(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	graph, err := graphGetByID(api, "graph.get", d.Id())
+	if err != nil {
+		return err
+	}
+
+	readGraph(d, *graph)
+
+	log.Printf("[DEBUG] Graph name is %s\n", graph.Name)
+	return nil
+}
+
+func resourceZabbixGraphExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	api := meta.(*providerMeta).api
+
+	_, err := graphGetByID(api, "graph.get", d.Id())
+	if err != nil {
+		if strings.Contains(err.Error(), "Expected exactly one result") {
+			log.Printf("[DEBUG] Graph with id %s doesn't exist", d.Id())
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func resourceZabbixGraphUpdate(d *schema.ResourceData, meta interface{}) error {
+	graph, err := createGraphObject(d)
+	if err != nil {
+		return err
+	}
+
+	graph.GraphID = d.Id()
+	return createRetry(d, meta, updateGraph, *graph, resourceZabbixGraphRead)
+}
+
+func resourceZabbixGraphDelete(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	return callDeleteByIDs(api, "graph.delete", []string{d.Id()}, "graphids")
+}
+
+// createGraphObject builds the graphs and graph prototypes, the items keep the
+// order of the graph_item blocks.
+func createGraphObject(d *schema.ResourceData) (*graphObject, error) {
+	graph := graphObject{
+		Name:           d.Get("name").(string),
+		Width:          strconv.Itoa(d.Get("width").(int)),
+		Height:         strconv.Itoa(d.Get("height").(int)),
+		GraphType:      strconv.Itoa(GraphTypes[d.Get("type").(string)]),
+		ShowLegend:     boolToString(d.Get("show_legend").(bool)),
+		ShowWorkPeriod: boolToString(d.Get("show_work_period").(bool)),
+		ShowTriggers:   boolToString(d.Get("show_triggers").(bool)),
+		Show3D:         boolToString(d.Get("show_3d").(bool)),
+		PercentLeft:    floatToString(d.Get("percent_left").(float64)),
+		PercentRight:   floatToString(d.Get("percent_right").(float64)),
+		YMinType:       strconv.Itoa(GraphYAxisTypes[d.Get("ymin_type").(string)]),
+		YAxisMin:       floatToString(d.Get("ymin").(float64)),
+		YMaxType:       strconv.Itoa(GraphYAxisTypes[d.Get("ymax_type").(string)]),
+		YAxisMax:       floatToString(d.Get("ymax").(float64)),
+	}
+
+	for _, side := range []string{"ymin", "ymax"} {
+		itemID := d.Get(side + "_item_id").(string)
+		if d.Get(side+"_type").(string) == "item" {
+			if itemID == "" {
+				return nil, fmt.Errorf("%s_item_id is required by the item %s_type", side, side)
+			}
+		} else if itemID != "" {
+			return nil, fmt.Errorf("%s_item_id is only used by the item %s_type", side, side)
+		}
+	}
+	graph.YMinItemID = d.Get("ymin_item_id").(string)
+	graph.YMaxItemID = d.Get("ymax_item_id").(string)
+
+	terraformItems := d.Get("graph_item").([]interface{})
+	graph.GraphItems = make([]graphItem, len(terraformItems))
+	for i, terraformItem := range terraformItems {
+		value := terraformItem.(map[string]interface{})
+		graph.GraphItems[i] = graphItem{
+			ItemID:    value["item_id"].(string),
+			Color:     strings.ToUpper(value["color"].(string)),
+			DrawType:  strconv.Itoa(GraphItemDrawTypes[value["draw_type"].(string)]),
+			SortOrder: strconv.Itoa(i),
+			YAxisSide: strconv.Itoa(GraphItemYAxisSides[value["yaxis_side"].(string)]),
+			CalcFnc:   strconv.Itoa(GraphItemCalcFunctions[value["calc_function"].(string)]),
+			Type:      strconv.Itoa(GraphItemTypes[value["type"].(string)]),
+		}
+	}
+	return &graph, nil
+}
+
+// readGraph sets the attributes of graphs and graph prototypes, the items are
+// sorted by their order.
+func readGraph(d *schema.ResourceData, graph graphObject) {
+	d.Set("name", graph.Name)
+	d.Set("width", atoi(graph.Width))
+	d.Set("height", atoi(graph.Height))
+	d.Set("type", enumName(GraphTypes, graph.GraphType))
+	d.Set("show_legend", graph.ShowLegend == "1")
+	d.Set("show_work_period", graph.ShowWorkPeriod == "1")
+	d.Set("show_triggers", graph.ShowTriggers == "1")
+	d.Set("show_3d", graph.Show3D == "1")
+	d.Set("percent_left", stringToFloat(graph.PercentLeft))
+	d.Set("percent_right", stringToFloat(graph.PercentRight))
+	d.Set("ymin_type", enumName(GraphYAxisTypes, graph.YMinType))
+	d.Set("ymin", stringToFloat(graph.YAxisMin))
+	d.Set("ymax_type", enumName(GraphYAxisTypes, graph.YMaxType))
+	d.Set("ymax", stringToFloat(graph.YAxisMax))
+
+	// Zabbix returns an item id of 0 without item
+	if graph.YMinItemID == "0" {
+		graph.YMinItemID = ""
+	}
+	if graph.YMaxItemID == "0" {
+		graph.YMaxItemID = ""
+	}
+	d.Set("ymin_item_id", graph.YMinItemID)
+	d.Set("ymax_item_id", graph.YMaxItemID)
+
+	sort.SliceStable(graph.GraphItems, func(i, j int) bool {
+		return atoi(graph.GraphItems[i].SortOrder) < atoi(graph.GraphItems[j].SortOrder)
+	})
+	terraformItems := make([]interface{}, len(graph.GraphItems))
+	for i, item := range graph.GraphItems {
+		terraformItems[i] = map[string]interface{}{
+			"item_id":       item.ItemID,
+			"color":         strings.ToUpper(item.Color),
+			"draw_type":     enumName(GraphItemDrawTypes, item.DrawType),
+			"yaxis_side":    enumName(GraphItemYAxisSides, item.YAxisSide),
+			"calc_function": enumName(GraphItemCalcFunctions, item.CalcFnc),
+			"type":          enumName(GraphItemTypes, item.Type),
+		}
+	}
+	d.Set("graph_item", terraformItems)
+}
+
+func createGraph(graph interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "graph.create", []graphObject{graph.(graphObject)}, "graphids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+func updateGraph(graph interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "graph.update", []graphObject{graph.(graphObject)}, "graphids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+// graphObject represent Zabbix graph object, of graphs and graph prototypes
+// https://www.zabbix.com/documentation/current/manual/api/reference/graph/object
+type graphObject struct {
+	GraphID        string      `json:"graphid,omitempty"`
+	Name           string      `json:"name"`
+	Width          string      `json:"width"`
+	Height         string      `json:"height"`
+	GraphType      string      `json:"graphtype"`
+	ShowLegend     string      `json:"show_legend"`
+	ShowWorkPeriod string      `json:"show_work_period"`
+	ShowTriggers   string      `json:"show_triggers"`
+	Show3D         string      `json:"show_3d"`
+	PercentLeft    string      `json:"percent_left"`
+	PercentRight   string      `json:"percent_right"`
+	YMinType       string      `json:"ymin_type"`
+	YAxisMin       string      `json:"yaxismin"`
+	YMinItemID     string      `json:"ymin_itemid,omitempty"`
+	YMaxType       string      `json:"ymax_type"`
+	YAxisMax       string      `json:"yaxismax"`
+	YMaxItemID     string      `json:"ymax_itemid,omitempty"`
+	GraphItems     []graphItem `json:"gitems"`
+}
+
+// graphItem represent Zabbix graph item object
+// https://www.zabbix.com/documentation/current/manual/api/reference/graphitem/object
+type graphItem struct {
+	ItemID    string `json:"itemid"`
+	Color     string `json:"color"`
+	DrawType  string `json:"drawtype"`
+	SortOrder string `json:"sortorder"`
+	YAxisSide string `json:"yaxisside"`
+	CalcFnc   string `json:"calc_fnc"`
+	Type      string `json:"type"`
+}
+
+// graphsGet calls graph.get or graphprototype.get, with the items of the
+// graphs
+func graphsGet(api *zabbix.API, method string, params zabbix.Params) (res []graphObject, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	params["selectGraphItems"] = "extend"
+	err = api.CallWithErrorParse(method, params, &res)
+	return
+}
+
+func graphGetByID(api *zabbix.API, method string, id string) (*graphObject, error) {
+	graphs, err := graphsGet(api, method, zabbix.Params{"graphids": id})
+	if err != nil {
+		return nil, err
+	}
+	if len(graphs) != 1 {
+		return nil, expectOneResult(len(graphs))
+	}
+	return &graphs[0], nil
+}
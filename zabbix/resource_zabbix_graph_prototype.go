@@ -0,0 +1,94 @@
+package zabbix
+
+import (
+	"log"
+	"strings"
+
+	"github.com/claranet/go-zabbix-api"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func resourceZabbixGraphPrototype() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceZabbixGraphPrototypeCreate,
+		Read:   resourceZabbixGraphPrototypeRead,
+		Exists: resourceZabbixGraphPrototypeExists,
+		Update: resourceZabbixGraphPrototypeUpdate,
+		Delete: resourceZabbixGraphPrototypeDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: schemaGraph("ID of the item or item prototype drawn, at least one item prototype is required."),
+	}
+}
+
+func resourceZabbixGraphPrototypeCreate(d *schema.ResourceData, meta interface{}) error {
+	graph, err := createGraphObject(d)
+	if err != nil {
+		return err
+	}
+
+	return createRetry(d, meta, createGraphPrototype, *graph, resourceZabbixGraphPrototypeRead)
+}
+
+func resourceZabbixGraphPrototypeRead(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	graph, err := graphGetByID(api, "graphprototype.get", d.Id())
+	if err != nil {
+		return err
+	}
+
+	readGraph(d, *graph)
+
+	log.Printf("[DEBUG] Graph prototype name is %s\n", graph.Name)
+	return nil
+}
+
+func resourceZabbixGraphPrototypeExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	api := meta.(*providerMeta).api
+
+	_, err := graphGetByID(api, "graphprototype.get", d.Id())
+	if err != nil {
+		if strings.Contains(err.Error(), "Expected exactly one result") {
+			log.Printf("[DEBUG] Graph prototype with id %s doesn't exist", d.Id())
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func resourceZabbixGraphPrototypeUpdate(d *schema.ResourceData, meta interface{}) error {
+	graph, err := createGraphObject(d)
+	if err != nil {
+		return err
+	}
+
+	graph.GraphID = d.Id()
+	return createRetry(d, meta, updateGraphPrototype, *graph, resourceZabbixGraphPrototypeRead)
+}
+
+func resourceZabbixGraphPrototypeDelete(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	return callDeleteByIDs(api, "graphprototype.delete", []string{d.Id()}, "graphids")
+}
+
+func createGraphPrototype(graph interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "graphprototype.create", []graphObject{graph.(graphObject)}, "graphids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+func updateGraphPrototype(graph interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "graphprototype.update", []graphObject{graph.(graphObject)}, "graphids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
@@ -0,0 +1,127 @@
+package zabbix
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccZabbixGraphPrototype_Basic(t *testing.T) {
+	strID := acctest.RandString(5)
+	resourceName := "zabbix_graph_prototype.graph_prototype_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixGraphPrototypeDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixGraphPrototypeConfig(strID, "normal", "line"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", "Traffic on {#IFNAME}"),
+					resource.TestCheckResourceAttr(resourceName, "type", "normal"),
+					resource.TestCheckResourceAttr(resourceName, "graph_item.#", "2"),
+					resource.TestCheckResourceAttrPair(resourceName, "graph_item.0.item_id", "zabbix_item_prototype.in", "id"),
+					resource.TestCheckResourceAttr(resourceName, "graph_item.1.draw_type", "line"),
+				),
+			},
+			{
+				Config: testAccZabbixGraphPrototypeConfig(strID, "stacked", "gradient_line"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "type", "stacked"),
+					resource.TestCheckResourceAttr(resourceName, "graph_item.1.draw_type", "gradient_line"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckZabbixGraphPrototypeDestroy(s *terraform.State) error {
+	api := testAccProvider.Meta().(*providerMeta).api
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "zabbix_graph_prototype" {
+			continue
+		}
+
+		_, err := graphGetByID(api, "graphprototype.get", rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Graph prototype still exists %s", rs.Primary.ID)
+		}
+
+		expectedError := "Expected exactly one result, got 0."
+		if err.Error() != expectedError {
+			return fmt.Errorf("expected error : %s, got : %s", expectedError, err.Error())
+		}
+	}
+	return nil
+}
+
+func testAccZabbixGraphPrototypeConfig(strID string, graphType string, drawType string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "host_group_test" {
+			name = "host_group_%s"
+		}
+
+		resource "zabbix_template" "template_test" {
+			host = "template_%s"
+			groups = [zabbix_host_group.host_group_test.name]
+		}
+
+		resource "zabbix_lld_rule" "lld_rule_test" {
+			delay = 3600
+			host_id = zabbix_template.template_test.id
+			key = "net.if.discovery"
+			name = "Network interfaces discovery"
+			type = "zabbix_agent"
+			filter {
+				condition {
+					macro = "{#IFNAME}"
+					value = "^lo$"
+				}
+				eval_type = 0
+			}
+		}
+
+		resource "zabbix_item_prototype" "in" {
+			delay = 60
+			host_id = zabbix_template.template_test.id
+			rule_id = zabbix_lld_rule.lld_rule_test.id
+			key = "net.if.in[{#IFNAME}]"
+			name = "Incoming traffic on {#IFNAME}"
+			value_type = "unsigned"
+		}
+
+		resource "zabbix_item_prototype" "out" {
+			delay = 60
+			host_id = zabbix_template.template_test.id
+			rule_id = zabbix_lld_rule.lld_rule_test.id
+			key = "net.if.out[{#IFNAME}]"
+			name = "Outgoing traffic on {#IFNAME}"
+			value_type = "unsigned"
+		}
+
+		resource "zabbix_graph_prototype" "graph_prototype_test" {
+			name = "Traffic on {#IFNAME}"
+			type = "%s"
+			graph_item {
+				item_id   = zabbix_item_prototype.in.id
+				color     = "00AA00"
+				draw_type = "filled_region"
+			}
+			graph_item {
+				item_id   = zabbix_item_prototype.out.id
+				color     = "3333FF"
+				draw_type = "%s"
+			}
+		}
+	`, strID, strID, graphType, drawType)
+}
@@ -0,0 +1,148 @@
+package zabbix
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccZabbixGraph_Basic(t *testing.T) {
+	strID := acctest.RandString(5)
+	resourceName := "zabbix_graph.graph_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixGraphDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixGraphConfig(strID, `
+					graph_item {
+						item_id = zabbix_item.item_test_0.id
+						color   = "00aa00"
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", fmt.Sprintf("graph_%s", strID)),
+					resource.TestCheckResourceAttr(resourceName, "type", "normal"),
+					resource.TestCheckResourceAttr(resourceName, "width", "900"),
+					resource.TestCheckResourceAttr(resourceName, "ymin_type", "calculated"),
+					resource.TestCheckResourceAttr(resourceName, "graph_item.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "graph_item.0.color", "00AA00"),
+					resource.TestCheckResourceAttr(resourceName, "graph_item.0.draw_type", "line"),
+					resource.TestCheckResourceAttr(resourceName, "graph_item.0.calc_function", "average"),
+				),
+			},
+			{
+				Config: testAccZabbixGraphConfig(strID, `
+					type          = "stacked"
+					width         = 600
+					height        = 300
+					show_triggers = false
+					ymin_type     = "fixed"
+					ymin          = 0
+					ymax_type     = "item"
+					ymax_item_id  = zabbix_item.item_test_1.id
+
+					graph_item {
+						item_id   = zabbix_item.item_test_1.id
+						color     = "AA0000"
+						draw_type = "filled_region"
+					}
+					graph_item {
+						item_id       = zabbix_item.item_test_0.id
+						color         = "00AA00"
+						draw_type     = "bold_line"
+						yaxis_side    = "right"
+						calc_function = "max"
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "type", "stacked"),
+					resource.TestCheckResourceAttr(resourceName, "height", "300"),
+					resource.TestCheckResourceAttr(resourceName, "show_triggers", "false"),
+					resource.TestCheckResourceAttr(resourceName, "ymax_type", "item"),
+					resource.TestCheckResourceAttrPair(resourceName, "ymax_item_id", "zabbix_item.item_test_1", "id"),
+					resource.TestCheckResourceAttr(resourceName, "graph_item.#", "2"),
+					resource.TestCheckResourceAttrPair(resourceName, "graph_item.0.item_id", "zabbix_item.item_test_1", "id"),
+					resource.TestCheckResourceAttr(resourceName, "graph_item.0.draw_type", "filled_region"),
+					resource.TestCheckResourceAttr(resourceName, "graph_item.1.yaxis_side", "right"),
+					resource.TestCheckResourceAttr(resourceName, "graph_item.1.calc_function", "max"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccZabbixGraphConfig(strID, `
+					ymin_type = "item"
+					graph_item {
+						item_id = zabbix_item.item_test_0.id
+						color   = "00AA00"
+					}
+				`),
+				ExpectError: regexp.MustCompile("ymin_item_id is required by the item ymin_type"),
+			},
+		},
+	})
+}
+
+func testAccCheckZabbixGraphDestroy(s *terraform.State) error {
+	api := testAccProvider.Meta().(*providerMeta).api
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "zabbix_graph" {
+			continue
+		}
+
+		_, err := graphGetByID(api, "graph.get", rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Graph still exists %s", rs.Primary.ID)
+		}
+
+		expectedError := "Expected exactly one result, got 0."
+		if err.Error() != expectedError {
+			return fmt.Errorf("expected error : %s, got : %s", expectedError, err.Error())
+		}
+	}
+	return nil
+}
+
+func testAccZabbixGraphConfig(strID string, graph string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "host_group_test" {
+			name = "host_group_%s"
+		}
+
+		resource "zabbix_template" "template_test" {
+			host = "template_%s"
+			groups = [zabbix_host_group.host_group_test.name]
+		}
+
+		resource "zabbix_item" "item_test_0" {
+			name = "item_test_0"
+			key = "system.cpu.load[all,avg1]"
+			delay = "60"
+			host_id = zabbix_template.template_test.id
+		}
+
+		resource "zabbix_item" "item_test_1" {
+			name = "item_test_1"
+			key = "system.cpu.num"
+			delay = "60"
+			value_type = "unsigned"
+			host_id = zabbix_template.template_test.id
+		}
+
+		resource "zabbix_graph" "graph_test" {
+			name = "graph_%s"
+			%s
+		}
+	`, strID, strID, strID, graph)
+}
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strconv"
 
 	"github.com/claranet/go-zabbix-api"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 )
 
 // HostInterfaceTypes zabbix different interface type
@@ -17,39 +19,164 @@ var HostInterfaceTypes = map[string]zabbix.InterfaceType{
 	"jmx":   4,
 }
 
+// HostInterfaceSNMPSecurityLevels zabbix different SNMPv3 security level
+var HostInterfaceSNMPSecurityLevels = map[string]int{
+	"noauthnopriv": 0,
+	"authnopriv":   1,
+	"authpriv":     2,
+}
+
+// HostInterfaceSNMPAuthProtocols zabbix different SNMPv3 authentication
+// protocol, only md5 and sha1 are supported before Zabbix 5.4
+var HostInterfaceSNMPAuthProtocols = map[string]int{
+	"md5":    0,
+	"sha1":   1,
+	"sha224": 2,
+	"sha256": 3,
+	"sha384": 4,
+	"sha512": 5,
+}
+
+// HostInterfaceSNMPPrivProtocols zabbix different SNMPv3 privacy protocol,
+// only des and aes128 are supported before Zabbix 5.4
+var HostInterfaceSNMPPrivProtocols = map[string]int{
+	"des":     0,
+	"aes128":  1,
+	"aes192":  2,
+	"aes256":  3,
+	"aes192c": 4,
+	"aes256c": 5,
+}
+
+// HostInventoryModes zabbix different host inventory mode
+var HostInventoryModes = map[string]int{
+	"disabled":  -1,
+	"manual":    0,
+	"automatic": 1,
+}
+
+// hostInventoryFields are the standard fields of the host inventory
+var hostInventoryFields = []string{
+	"type", "type_full", "name", "alias", "os", "os_full", "os_short",
+	"serialno_a", "serialno_b", "tag", "asset_tag", "macaddress_a", "macaddress_b",
+	"hardware", "hardware_full", "software", "software_full",
+	"software_app_a", "software_app_b", "software_app_c", "software_app_d", "software_app_e",
+	"contact", "location", "location_lat", "location_lon", "notes",
+	"chassis", "model", "hw_arch", "vendor", "contract_number", "installer_name",
+	"deployment_status", "url_a", "url_b", "url_c",
+	"host_networks", "host_netmask", "host_router", "oob_ip", "oob_netmask", "oob_router",
+	"date_hw_purchase", "date_hw_install", "date_hw_expiry", "date_hw_decomm",
+	"site_address_a", "site_address_b", "site_address_c", "site_city", "site_state",
+	"site_country", "site_zip", "site_rack", "site_notes",
+	"poc_1_name", "poc_1_email", "poc_1_phone_a", "poc_1_phone_b", "poc_1_cell", "poc_1_screen", "poc_1_notes",
+	"poc_2_name", "poc_2_email", "poc_2_phone_a", "poc_2_phone_b", "poc_2_cell", "poc_2_screen", "poc_2_notes",
+}
+
+func schemaHostInventory() *schema.Resource {
+	fields := map[string]*schema.Schema{}
+
+	for _, field := range hostInventoryFields {
+		fields[field] = &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		}
+	}
+
+	return &schema.Resource{Schema: fields}
+}
+
+var interfaceSNMPSchema *schema.Resource = &schema.Resource{
+	Schema: map[string]*schema.Schema{
+		"version": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      2,
+			ValidateFunc: validation.IntBetween(1, 3),
+		},
+		"bulk": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
+		"community": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "SNMP community of SNMPv1 and SNMPv2c interfaces.",
+		},
+		"security_name": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"security_level": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "noauthnopriv",
+			ValidateFunc: validation.StringInSlice([]string{"noauthnopriv", "authnopriv", "authpriv"}, false),
+		},
+		"auth_protocol": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "md5",
+			ValidateFunc: validation.StringInSlice([]string{"md5", "sha1", "sha224", "sha256", "sha384", "sha512"}, false),
+		},
+		"auth_passphrase": &schema.Schema{
+			Type:      schema.TypeString,
+			Optional:  true,
+			Sensitive: true,
+		},
+		"priv_protocol": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "des",
+			ValidateFunc: validation.StringInSlice([]string{"des", "aes128", "aes192", "aes256", "aes192c", "aes256c"}, false),
+		},
+		"priv_passphrase": &schema.Schema{
+			Type:      schema.TypeString,
+			Optional:  true,
+			Sensitive: true,
+		},
+		"context_name": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+	},
+}
+
 var interfaceSchema *schema.Resource = &schema.Resource{
 	Schema: map[string]*schema.Schema{
 		"dns": &schema.Schema{
 			Type:     schema.TypeString,
 			Optional: true,
-			ForceNew: true,
 		},
 		"ip": &schema.Schema{
 			Type:     schema.TypeString,
 			Optional: true,
-			ForceNew: true,
 		},
 		"main": &schema.Schema{
 			Type:     schema.TypeBool,
 			Required: true,
-			ForceNew: true,
 		},
 		"port": &schema.Schema{
 			Type:     schema.TypeString,
 			Optional: true,
 			Default:  "10050",
-			ForceNew: true,
 		},
 		"type": &schema.Schema{
 			Type:     schema.TypeString,
 			Optional: true,
 			Default:  "agent",
-			ForceNew: true,
 		},
 		"interface_id": &schema.Schema{
 			Type:     schema.TypeString,
 			Computed: true,
-			ForceNew: true,
+		},
+		"snmp": &schema.Schema{
+			Type:        schema.TypeList,
+			Elem:        interfaceSNMPSchema,
+			Optional:    true,
+			Computed:    true,
+			MaxItems:    1,
+			Description: "Details of SNMP interfaces, only supported on Zabbix 5.0+.",
 		},
 	},
 }
@@ -60,6 +187,9 @@ func resourceZabbixHost() *schema.Resource {
 		Read:   resourceZabbixHostRead,
 		Update: resourceZabbixHostUpdate,
 		Delete: resourceZabbixHostDelete,
+
+		CustomizeDiff: resourceZabbixHostCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			"host": &schema.Schema{
 				Type:        schema.TypeString,
@@ -84,14 +214,12 @@ func resourceZabbixHost() *schema.Resource {
 				Default:  true,
 				Optional: true,
 			},
-			//any changes to interface will trigger recreate, zabbix api kinda doesn't
-			//work nicely, interface can get linked to various things and replacement
-			//simply doesn't work
+			//interfaces are reconciled by type and address with hostinterface.create/update/delete,
+			//an interface is only recreated when zabbix refuses to change its type
 			"interfaces": &schema.Schema{
 				Type:     schema.TypeList,
 				Elem:     interfaceSchema,
 				Required: true,
-				ForceNew: true,
 			},
 			"groups": &schema.Schema{
 				Type:     schema.TypeSet,
@@ -103,14 +231,46 @@ func resourceZabbixHost() *schema.Resource {
 				Elem:     &schema.Schema{Type: schema.TypeString},
 				Optional: true,
 			},
+			"proxy": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Name or ID of the proxy monitoring the host.",
+			},
+			"inventory_mode": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Host inventory population mode, defaults to the global setting of Zabbix.",
+				ValidateFunc: validation.StringInSlice([]string{"disabled", "manual", "automatic"}, false),
+			},
+			"inventory": &schema.Schema{
+				Type:     schema.TypeList,
+				Elem:     schemaHostInventory(),
+				Optional: true,
+				MaxItems: 1,
+			},
+			// named like the blocks of templates, where macro is the
+			// deprecated map
+			"user_macro": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        schemaUserMacro(),
+				Optional:    true,
+				Description: "User macros of the host, only read and managed once set.",
+			},
+			"tag": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        schemaHostTag(),
+				Optional:    true,
+				Description: "Tags of the host, only supported on Zabbix 4.2+.",
+			},
 		},
 	}
 }
 
-func getInterfaces(d *schema.ResourceData) (zabbix.HostInterfaces, error) {
+func getInterfaces(d *schema.ResourceData, zabbixVersion string) ([]hostInterface, error) {
 	interfaceCount := d.Get("interfaces.#").(int)
 
-	interfaces := make(zabbix.HostInterfaces, interfaceCount)
+	interfaces := make([]hostInterface, interfaceCount)
 
 	for i := 0; i < interfaceCount; i++ {
 		prefix := fmt.Sprintf("interfaces.%d.", i)
@@ -139,36 +299,210 @@ func getInterfaces(d *schema.ResourceData) (zabbix.HostInterfaces, error) {
 		main := 1
 
 		if !d.Get(prefix + "main").(bool) {
-			main = 1
+			main = 0
 		}
 
-		interfaces[i] = zabbix.HostInterface{
+		interfaces[i] = hostInterface{
 			IP:    ip,
 			DNS:   dns,
-			Main:  main,
+			Main:  strconv.Itoa(main),
 			Port:  d.Get(prefix + "port").(string),
-			Type:  typeID,
-			UseIP: useip,
+			Type:  strconv.Itoa(int(typeID)),
+			UseIP: strconv.Itoa(useip),
+		}
+
+		//the snmp details are refused by CustomizeDiff before Zabbix 5.0
+		if typeID != HostInterfaceTypes["snmp"] || !isZabbixServerVersion50OrHigher(zabbixVersion) {
+			continue
 		}
+
+		details, err := getInterfaceSNMPDetails(d.Get(prefix + "snmp").([]interface{}))
+
+		if err != nil {
+			return nil, fmt.Errorf("%ssnmp: %s", prefix, err)
+		}
+
+		interfaces[i].Details = details
 	}
 
 	return interfaces, nil
 }
 
+// getInterfaceSNMPDetails builds the details required by SNMP interfaces since
+// Zabbix 5.0, an interface without snmp block uses SNMPv2c with the
+// {$SNMP_COMMUNITY} macro like the interfaces of the previous versions.
+func getInterfaceSNMPDetails(snmp []interface{}) (*hostInterfaceDetails, error) {
+	if len(snmp) == 0 || snmp[0] == nil {
+		return &hostInterfaceDetails{
+			Version:   "2",
+			Bulk:      "1",
+			Community: "{$SNMP_COMMUNITY}",
+		}, nil
+	}
+
+	value := snmp[0].(map[string]interface{})
+	version := value["version"].(int)
+	details := hostInterfaceDetails{
+		Version: strconv.Itoa(version),
+		Bulk:    boolToString(value["bulk"].(bool)),
+	}
+
+	if version != 3 {
+		if value["community"].(string) == "" {
+			return nil, fmt.Errorf("community is required by SNMPv%d", version)
+		}
+		for _, key := range []string{"security_name", "auth_passphrase", "priv_passphrase", "context_name"} {
+			if value[key].(string) != "" {
+				return nil, fmt.Errorf("%s is only used by SNMPv3", key)
+			}
+		}
+		details.Community = value["community"].(string)
+		return &details, nil
+	}
+
+	if value["community"].(string) != "" {
+		return nil, fmt.Errorf("community is only used by SNMPv1 and SNMPv2c")
+	}
+
+	securityLevel := value["security_level"].(string)
+	authProtocol := HostInterfaceSNMPAuthProtocols[value["auth_protocol"].(string)]
+	privProtocol := HostInterfaceSNMPPrivProtocols[value["priv_protocol"].(string)]
+
+	if securityLevel != "noauthnopriv" && value["auth_passphrase"].(string) == "" {
+		return nil, fmt.Errorf("auth_passphrase is required by the %s security level", securityLevel)
+	}
+	if securityLevel == "authpriv" && value["priv_passphrase"].(string) == "" {
+		return nil, fmt.Errorf("priv_passphrase is required by the authpriv security level")
+	}
+
+	details.SecurityName = value["security_name"].(string)
+	details.SecurityLevel = strconv.Itoa(HostInterfaceSNMPSecurityLevels[securityLevel])
+	details.AuthProtocol = strconv.Itoa(authProtocol)
+	details.AuthPassphrase = value["auth_passphrase"].(string)
+	details.PrivProtocol = strconv.Itoa(privProtocol)
+	details.PrivPassphrase = value["priv_passphrase"].(string)
+	details.ContextName = value["context_name"].(string)
+
+	return &details, nil
+}
+
+// resourceZabbixHostCustomizeDiff refuses the snmp details not supported by
+// the server at plan time.
+func resourceZabbixHostCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	zabbixVersion := getZabbixServerVersion(meta)
+
+	for i, value := range d.Get("interfaces").([]interface{}) {
+		hostInterface, ok := value.(map[string]interface{})
+
+		if !ok || hostInterface["type"] != "snmp" {
+			continue
+		}
+
+		snmp := hostInterface["snmp"].([]interface{})
+
+		if len(snmp) == 0 || snmp[0] == nil {
+			continue
+		}
+
+		if !isZabbixServerVersion50OrHigher(zabbixVersion) {
+			return fmt.Errorf("interfaces.%d.snmp is only supported from Zabbix 5.0, got %s", i, zabbixVersion)
+		}
+
+		details := snmp[0].(map[string]interface{})
+
+		if HostInterfaceSNMPAuthProtocols[details["auth_protocol"].(string)] > 1 || HostInterfaceSNMPPrivProtocols[details["priv_protocol"].(string)] > 1 {
+			if !isZabbixServerVersion54OrHigher(zabbixVersion) {
+				return fmt.Errorf("interfaces.%d.snmp: only the md5 and sha1 auth_protocol and the des and aes128 priv_protocol are supported before Zabbix 5.4, got %s", i, zabbixVersion)
+			}
+		}
+	}
+
+	return nil
+}
+
+// getHostGroups returns the ids of the groups given by id or by name,
+// referencing groups by id keeps hosts and templates valid when the groups are
+// renamed.
 func getHostGroups(d *schema.ResourceData, api *zabbix.API) (zabbix.HostGroupIDs, error) {
-	configGroups := d.Get("groups").(*schema.Set)
-	setHostGroups := make([]string, configGroups.Len())
+	var ids, names []string
+	for _, g := range getStringSet(d, "groups") {
+		if _, err := strconv.Atoi(g); err == nil {
+			ids = append(ids, g)
+		} else {
+			names = append(names, g)
+		}
+	}
 
-	for i, g := range configGroups.List() {
-		setHostGroups[i] = g.(string)
+	var groups zabbix.HostGroups
+	if len(ids) > 0 {
+		groupsByID, err := api.HostGroupsGet(zabbix.Params{
+			"output":   "extend",
+			"groupids": ids,
+		})
+		if err != nil {
+			return nil, err
+		}
+		// the ids not found are the names of groups named with digits
+		for _, id := range ids {
+			found := false
+			for _, g := range groupsByID {
+				if g.GroupID == id {
+					found = true
+					break
+				}
+			}
+			if !found {
+				names = append(names, id)
+			}
+		}
+		groups = append(groups, groupsByID...)
+	}
+
+	if len(names) > 0 {
+		groupsByName, err := getHostGroupsByName(api, names)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, groupsByName...)
+	}
+
+	hostGroups := make(zabbix.HostGroupIDs, len(groups))
+
+	for i, g := range groups {
+		hostGroups[i] = zabbix.HostGroupID{
+			GroupID: g.GroupID,
+		}
 	}
 
-	log.Printf("[DEBUG] Groups %v\n", setHostGroups)
+	return hostGroups, nil
+}
+
+// readHostGroups returns the groups of the host or template by id when they
+// are referenced by id, by name otherwise.
+func readHostGroups(d *schema.ResourceData, groups zabbix.HostGroups) []string {
+	configured := map[string]bool{}
+	for _, g := range getStringSet(d, "groups") {
+		configured[g] = true
+	}
+
+	values := make([]string, len(groups))
+	for i, g := range groups {
+		if configured[g.GroupID] && !configured[g.Name] {
+			values[i] = g.GroupID
+		} else {
+			values[i] = g.Name
+		}
+	}
+	return values
+}
+
+func getHostGroupsByName(api *zabbix.API, names []string) (zabbix.HostGroups, error) {
+	log.Printf("[DEBUG] Groups %v\n", names)
 
 	groupParams := zabbix.Params{
 		"output": "extend",
 		"filter": map[string]interface{}{
-			"name": setHostGroups,
+			"name": names,
 		},
 	}
 
@@ -178,10 +512,10 @@ func getHostGroups(d *schema.ResourceData, api *zabbix.API) (zabbix.HostGroupIDs
 		return nil, err
 	}
 
-	if len(groups) < configGroups.Len() {
+	if len(groups) < len(names) {
 		log.Printf("[DEBUG] Not all of the specified groups were found on zabbix server")
 
-		for _, n := range configGroups.List() {
+		for _, n := range names {
 			found := false
 
 			for _, g := range groups {
@@ -198,15 +532,45 @@ func getHostGroups(d *schema.ResourceData, api *zabbix.API) (zabbix.HostGroupIDs
 		}
 	}
 
-	hostGroups := make(zabbix.HostGroupIDs, len(groups))
+	return groups, nil
+}
 
-	for i, g := range groups {
-		hostGroups[i] = zabbix.HostGroupID{
-			GroupID: g.GroupID,
+func getHostsByName(api *zabbix.API, names []string) (zabbix.Hosts, error) {
+	log.Printf("[DEBUG] Hosts %v\n", names)
+
+	hostParams := zabbix.Params{
+		"output": "extend",
+		"filter": map[string]interface{}{
+			"host": names,
+		},
+	}
+
+	hosts, err := api.HostsGet(hostParams)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(hosts) < len(names) {
+		log.Printf("[DEBUG] Not all of the specified hosts were found on zabbix server")
+
+		for _, n := range names {
+			found := false
+
+			for _, h := range hosts {
+				if n == h.Host {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return nil, fmt.Errorf("Host %s doesnt exist in zabbix server", n)
+			}
 		}
 	}
 
-	return hostGroups, nil
+	return hosts, nil
 }
 
 func getTemplates(d *schema.ResourceData, api *zabbix.API) (zabbix.TemplateIDs, error) {
@@ -267,11 +631,14 @@ func getTemplates(d *schema.ResourceData, api *zabbix.API) (zabbix.TemplateIDs,
 	return hostTemplates, nil
 }
 
-func createHostObj(d *schema.ResourceData, api *zabbix.API) (*zabbix.Host, error) {
-	host := zabbix.Host{
-		Host:   d.Get("host").(string),
-		Name:   d.Get("name").(string),
-		Status: 0,
+func createHostObj(d *schema.ResourceData, api *zabbix.API, zabbixVersion string) (*hostObject, error) {
+	host := hostObject{
+		Host: zabbix.Host{
+			Host:   d.Get("host").(string),
+			Name:   d.Get("name").(string),
+			Status: 0,
+		},
+		ProxyHostID: "0",
 	}
 
 	//0 is monitored, 1 - unmonitored host
@@ -287,7 +654,7 @@ func createHostObj(d *schema.ResourceData, api *zabbix.API) (*zabbix.Host, error
 
 	host.GroupIds = hostGroups
 
-	interfaces, err := getInterfaces(d)
+	interfaces, err := getInterfaces(d, zabbixVersion)
 
 	if err != nil {
 		return nil, err
@@ -302,45 +669,92 @@ func createHostObj(d *schema.ResourceData, api *zabbix.API) (*zabbix.Host, error
 	}
 
 	host.TemplateIDs = templates
+
+	if proxy := d.Get("proxy").(string); proxy != "" {
+		host.ProxyHostID, err = getProxyID(api, proxy)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = getInventory(d, &host)
+
+	if err != nil {
+		return nil, err
+	}
+
+	//the macros are only managed once user_macro is set, so that the macros
+	//added outside terraform to other hosts are left untouched
+	if d.HasChange("user_macro") {
+		macros, err := createUserMacros(d, zabbixVersion)
+
+		if err != nil {
+			return nil, err
+		}
+
+		host.Macros = &macros
+	}
+
+	host.Tags, err = createHostTags(d, zabbixVersion)
+
+	if err != nil {
+		return nil, err
+	}
+
 	return &host, nil
 }
 
 func resourceZabbixHostCreate(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
-	host, err := createHostObj(d, api)
+	host, err := createHostObj(d, api, getZabbixServerVersion(meta))
 
 	if err != nil {
 		return err
 	}
 
-	hosts := zabbix.Hosts{*host}
-
-	err = api.HostsCreate(hosts)
+	hostIDs, err := callCreate(api, "host.create", []hostObject{*host}, "hostids")
 
 	if err != nil {
 		return err
 	}
 
-	log.Printf("[DEBUG] Created host id is %s", hosts[0].HostID)
+	log.Printf("[DEBUG] Created host id is %s", hostIDs[0])
 
-	d.Set("host_id", hosts[0].HostID)
-	d.SetId(hosts[0].HostID)
+	d.Set("host_id", hostIDs[0])
+	d.SetId(hostIDs[0])
 
-	return nil
+	return resourceZabbixHostRead(d, meta)
 }
 
 func resourceZabbixHostRead(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	log.Printf("[DEBUG] Will read host with id %s", d.Get("host_id").(string))
 
-	host, err := api.HostGetByID(d.Get("host_id").(string))
+	params := zabbix.Params{
+		"hostids":         d.Get("host_id").(string),
+		"selectInventory": "extend",
+		"selectMacros":    "extend",
+	}
+
+	if isZabbixServerVersion42OrHigher(getZabbixServerVersion(meta)) {
+		params["selectTags"] = "extend"
+	}
+
+	hosts, err := hostsGet(api, params)
 
 	if err != nil {
 		return err
 	}
 
+	if len(hosts) != 1 {
+		return expectOneResult(len(hosts))
+	}
+
+	host := hosts[0]
+
 	log.Printf("[DEBUG] Host name is %s", host.Name)
 
 	d.Set("host", host.Host)
@@ -348,7 +762,29 @@ func resourceZabbixHostRead(d *schema.ResourceData, meta interface{}) error {
 
 	d.Set("monitored", host.Status == 0)
 
-	params := zabbix.Params{
+	err = readHostProxy(d, api, host.ProxyHostID)
+
+	if err != nil {
+		return err
+	}
+
+	err = readHostInterfaces(d, api)
+
+	if err != nil {
+		return err
+	}
+
+	readHostInventory(d, host)
+
+	if host.Macros != nil && d.Get("user_macro").(*schema.Set).Len() > 0 {
+		d.Set("user_macro", readUserMacros(d, *host.Macros))
+	}
+
+	if host.Tags != nil {
+		d.Set("tag", readHostTags(*host.Tags))
+	}
+
+	params = zabbix.Params{
 		"output": "extend",
 		"hostids": []string{
 			d.Id(),
@@ -375,21 +811,15 @@ func resourceZabbixHostRead(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	groupNames := make([]string, len(groups))
-
-	for i, g := range groups {
-		groupNames[i] = g.Name
-	}
-
-	d.Set("groups", groupNames)
+	d.Set("groups", readHostGroups(d, groups))
 
 	return nil
 }
 
 func resourceZabbixHostUpdate(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
-	host, err := createHostObj(d, api)
+	host, err := createHostObj(d, api, getZabbixServerVersion(meta))
 
 	if err != nil {
 		return err
@@ -397,25 +827,538 @@ func resourceZabbixHostUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	host.HostID = d.Id()
 
-	//interfaces can't be updated, changes will trigger recreate
-	//sending previous values will also fail the update
+	if d.HasChange("interfaces") {
+		err = updateHostInterfaces(d, api, host.Interfaces)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	//sending the interfaces would replace them and unlink their items
 	host.Interfaces = nil
 
-	hosts := zabbix.Hosts{*host}
+	_, err = api.CallWithError("host.update", []hostObject{*host})
 
-	err = api.HostsUpdate(hosts)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Created host id is %s", host.HostID)
+
+	return resourceZabbixHostRead(d, meta)
+}
+
+// readHostInterfaces sets the interfaces in the order of the state, the new
+// interfaces of the host are added at the end.
+func readHostInterfaces(d *schema.ResourceData, api *zabbix.API) error {
+	interfaces, err := hostInterfacesGet(api, zabbix.Params{
+		"output":    "extend",
+		"hostids":   d.Id(),
+		"sortfield": "interfaceid",
+	})
 
 	if err != nil {
 		return err
 	}
 
-	log.Printf("[DEBUG] Created host id is %s", hosts[0].HostID)
+	sorted := make([]hostInterface, 0, len(interfaces))
+	stateInterfaces := map[string]map[string]interface{}{}
+	stateList := d.Get("interfaces").([]interface{})
+
+	for _, i := range stateList {
+		id := i.(map[string]interface{})["interface_id"].(string)
+		stateInterfaces[id] = i.(map[string]interface{})
+
+		for _, hostInterface := range interfaces {
+			if hostInterface.InterfaceID == id {
+				sorted = append(sorted, hostInterface)
+			}
+		}
+	}
+
+	for _, hostInterface := range interfaces {
+		found := false
+
+		for _, s := range sorted {
+			if s.InterfaceID == hostInterface.InterfaceID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			sorted = append(sorted, hostInterface)
+		}
+	}
+
+	terraformInterfaces := make([]interface{}, len(sorted))
+
+	for i, hostInterface := range sorted {
+		terraformInterface := map[string]interface{}{
+			"interface_id": hostInterface.InterfaceID,
+			"ip":           hostInterface.IP,
+			"dns":          hostInterface.DNS,
+			"main":         hostInterface.Main == "1",
+			"port":         hostInterface.Port,
+		}
+
+		for name, typeID := range HostInterfaceTypes {
+			if strconv.Itoa(int(typeID)) == hostInterface.Type {
+				terraformInterface["type"] = name
+			}
+		}
+
+		//the interfaces created from the configuration have no id yet, they
+		//are returned in the order of the configuration
+		stateInterface, ok := stateInterfaces[hostInterface.InterfaceID]
+		if !ok && i < len(stateList) {
+			if value, ok := stateList[i].(map[string]interface{}); ok && value["interface_id"].(string) == "" {
+				stateInterface = value
+			}
+		}
+
+		terraformInterface["snmp"] = readInterfaceSNMPDetails(hostInterface.Details, stateInterface)
+
+		terraformInterfaces[i] = terraformInterface
+	}
+
+	return d.Set("interfaces", terraformInterfaces)
+}
+
+// readInterfaceSNMPDetails returns the snmp block of an interface, the
+// passphrases are kept from the state.
+func readInterfaceSNMPDetails(details interface{}, stateInterface map[string]interface{}) []interface{} {
+	value, ok := details.(map[string]interface{})
+
+	if !ok {
+		return []interface{}{}
+	}
+
+	snmp := map[string]interface{}{
+		"version":        atoi(fmt.Sprintf("%v", value["version"])),
+		"bulk":           value["bulk"] == "1",
+		"community":      value["community"],
+		"security_name":  value["securityname"],
+		"context_name":   value["contextname"],
+		"security_level": "noauthnopriv",
+		"auth_protocol":  "md5",
+		"priv_protocol":  "des",
+	}
+
+	for name, level := range HostInterfaceSNMPSecurityLevels {
+		if strconv.Itoa(level) == value["securitylevel"] {
+			snmp["security_level"] = name
+		}
+	}
+
+	for name, protocol := range HostInterfaceSNMPAuthProtocols {
+		if strconv.Itoa(protocol) == value["authprotocol"] {
+			snmp["auth_protocol"] = name
+		}
+	}
+
+	for name, protocol := range HostInterfaceSNMPPrivProtocols {
+		if strconv.Itoa(protocol) == value["privprotocol"] {
+			snmp["priv_protocol"] = name
+		}
+	}
+
+	if stateInterface != nil {
+		if stateSNMP, ok := stateInterface["snmp"].([]interface{}); ok && len(stateSNMP) > 0 && stateSNMP[0] != nil {
+			snmp["auth_passphrase"] = stateSNMP[0].(map[string]interface{})["auth_passphrase"]
+			snmp["priv_passphrase"] = stateSNMP[0].(map[string]interface{})["priv_passphrase"]
+		}
+	}
+
+	return []interface{}{snmp}
+}
+
+// updateHostInterfaces reconciles the interfaces of the host with the ones of
+// the state, see matchHostInterfaces. The new interfaces are created first, then
+// the matched ones are updated and the others deleted, so the main interface of
+// a type can move to another interface. Keeping the interface ids preserves the
+// items bound to them.
+func updateHostInterfaces(d *schema.ResourceData, api *zabbix.API, interfaces []hostInterface) error {
+	old, _ := d.GetChange("interfaces")
+	oldInterfaces := []hostInterface{}
+
+	for _, i := range old.([]interface{}) {
+		value := i.(map[string]interface{})
+
+		if value["interface_id"].(string) == "" {
+			continue
+		}
+
+		oldInterfaces = append(oldInterfaces, hostInterface{
+			InterfaceID: value["interface_id"].(string),
+			IP:          value["ip"].(string),
+			DNS:         value["dns"].(string),
+			Port:        value["port"].(string),
+			Main:        boolToString(value["main"].(bool)),
+			Type:        strconv.Itoa(int(HostInterfaceTypes[value["type"].(string)])),
+		})
+	}
+
+	matches := matchHostInterfaces(oldInterfaces, interfaces)
+
+	oldTypes := map[string]bool{}
+	newTypes := map[string]bool{}
+
+	for _, hostInterface := range oldInterfaces {
+		oldTypes[hostInterface.Type] = true
+	}
+	for _, hostInterface := range interfaces {
+		newTypes[hostInterface.Type] = true
+	}
+
+	var toCreate, typeChanged []hostInterface
+	var toUpdate []interface{}
+	var toDelete []string
+	var mainCreated []int
+	matched := make([]bool, len(oldInterfaces))
+
+	for i, hostInterface := range interfaces {
+		j := matches[i]
+
+		if j < 0 {
+			//the host already has a main interface of this type, the new
+			//interface becomes the main one in the update
+			if hostInterface.Main == "1" && oldTypes[hostInterface.Type] {
+				hostInterface.Main = "0"
+				mainCreated = append(mainCreated, len(toCreate))
+			}
+
+			hostInterface.HostID = d.Id()
+			toCreate = append(toCreate, hostInterface)
+			continue
+		}
+
+		matched[j] = true
+		hostInterface.InterfaceID = oldInterfaces[j].InterfaceID
+
+		if hostInterface.Type != oldInterfaces[j].Type {
+			typeChanged = append(typeChanged, hostInterface)
+		} else {
+			toUpdate = append(toUpdate, hostInterface)
+		}
+	}
+
+	for j, hostInterface := range oldInterfaces {
+		if matched[j] {
+			continue
+		}
+
+		toDelete = append(toDelete, hostInterface.InterfaceID)
+
+		//the main interface moves to another interface before the deletion
+		if hostInterface.Main == "1" && newTypes[hostInterface.Type] {
+			toUpdate = append(toUpdate, map[string]string{
+				"interfaceid": hostInterface.InterfaceID,
+				"main":        "0",
+			})
+		}
+	}
+
+	if len(toCreate) > 0 {
+		ids, err := callCreate(api, "hostinterface.create", toCreate, "interfaceids")
+
+		if err != nil {
+			return err
+		}
+
+		for _, i := range mainCreated {
+			toUpdate = append(toUpdate, map[string]string{
+				"interfaceid": ids[i],
+				"main":        "1",
+			})
+		}
+	}
+
+	for _, hostInterface := range typeChanged {
+		err := updateHostInterface(api, d.Id(), hostInterface)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(toUpdate) > 0 {
+		_, err := api.CallWithError("hostinterface.update", toUpdate)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(toDelete) > 0 {
+		err := callDeleteByIDs(api, "hostinterface.delete", toDelete, "interfaceids")
+
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// matchHostInterfaces returns the index of the old interface matching each new
+// interface, or -1 when the interface is new. The interfaces are matched by
+// type and address first, then by type in their order, so changing the address
+// of an interface updates it, and the remaining ones by position.
+func matchHostInterfaces(oldInterfaces []hostInterface, interfaces []hostInterface) []int {
+	matches := make([]int, len(interfaces))
+	used := make([]bool, len(oldInterfaces))
+
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	sameAddress := func(a, b hostInterface) bool {
+		return a.Type == b.Type && a.IP == b.IP && a.DNS == b.DNS && a.Port == b.Port
+	}
+	sameType := func(a, b hostInterface) bool {
+		return a.Type == b.Type
+	}
+	anyInterface := func(a, b hostInterface) bool {
+		return true
+	}
+
+	for _, match := range []func(a, b hostInterface) bool{sameAddress, sameType, anyInterface} {
+		for i, hostInterface := range interfaces {
+			if matches[i] >= 0 {
+				continue
+			}
+
+			for j, oldInterface := range oldInterfaces {
+				if !used[j] && match(oldInterface, hostInterface) {
+					matches[i] = j
+					used[j] = true
+					break
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+// updateHostInterface updates an interface whose type changed, and recreates it
+// when zabbix refuses the new type. Any other error is returned.
+func updateHostInterface(api *zabbix.API, hostID string, obj hostInterface) error {
+	_, err := api.CallWithError("hostinterface.update", []hostInterface{obj})
+
+	if err == nil {
+		return nil
+	}
+
+	//zabbix refuses the invalid parameters of the update with this code
+	if apiErr, ok := err.(*zabbix.Error); !ok || apiErr.Code != -32602 {
+		return err
+	}
+
+	log.Printf("[DEBUG] Failed to change the type of interface %s, got error %s, it will be recreated", obj.InterfaceID, err.Error())
+
+	deleteErr := callDeleteByIDs(api, "hostinterface.delete", []string{obj.InterfaceID}, "interfaceids")
+
+	if deleteErr != nil {
+		return fmt.Errorf("Failed to update interface %s: %s, and to recreate it: %s", obj.InterfaceID, err.Error(), deleteErr.Error())
+	}
+
+	obj.InterfaceID = ""
+	obj.HostID = hostID
+
+	_, err = callCreate(api, "hostinterface.create", []hostInterface{obj}, "interfaceids")
+
+	return err
+}
+
 func resourceZabbixHostDelete(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	return api.HostsDeleteByIds([]string{d.Id()})
 }
+
+// getInventory sets the inventory of the host when the inventory is enabled.
+// In automatic mode, the fields populated by items are rejected by Zabbix, so
+// only the configured fields are sent.
+func getInventory(d *schema.ResourceData, host *hostObject) error {
+	mode, ok := d.GetOk("inventory_mode")
+	inventory := d.Get("inventory").([]interface{})
+
+	if !ok {
+		if len(inventory) > 0 {
+			return errors.New("inventory_mode must be set to manual or automatic to set the inventory")
+		}
+		return nil
+	}
+
+	host.InventoryMode = strconv.Itoa(HostInventoryModes[mode.(string)])
+
+	if len(inventory) == 0 || inventory[0] == nil {
+		//clear the fields of a removed inventory block
+		if mode.(string) == "manual" && d.HasChange("inventory") {
+			fields := map[string]string{}
+
+			for _, field := range hostInventoryFields {
+				fields[field] = ""
+			}
+
+			host.Inventory = fields
+		}
+
+		return nil
+	}
+
+	if mode.(string) == "disabled" {
+		return errors.New("inventory can't be set when inventory_mode is disabled")
+	}
+
+	fields := map[string]string{}
+
+	for key, value := range inventory[0].(map[string]interface{}) {
+		if value.(string) != "" || mode.(string) == "manual" {
+			fields[key] = value.(string)
+		}
+	}
+
+	host.Inventory = fields
+
+	return nil
+}
+
+// readHostInventory sets the inventory of the host. In automatic mode, the
+// fields which are not configured are ignored as they are populated by items.
+func readHostInventory(d *schema.ResourceData, host hostObject) {
+	inventory, _ := host.Inventory.(map[string]interface{})
+
+	mode := host.InventoryMode
+
+	//before Zabbix 4.0, the mode is only returned in the inventory
+	if mode == "" {
+		mode = "-1"
+
+		if inventoryMode, ok := inventory["inventory_mode"].(string); ok {
+			mode = inventoryMode
+		}
+	}
+
+	for name, inventoryMode := range HostInventoryModes {
+		if strconv.Itoa(inventoryMode) == mode {
+			d.Set("inventory_mode", name)
+		}
+	}
+
+	if len(inventory) == 0 || mode == "-1" {
+		d.Set("inventory", []interface{}{})
+		return
+	}
+
+	configured := map[string]interface{}{}
+
+	if stateInventory := d.Get("inventory").([]interface{}); len(stateInventory) > 0 && stateInventory[0] != nil {
+		configured = stateInventory[0].(map[string]interface{})
+	}
+
+	fields := map[string]interface{}{}
+	empty := true
+
+	for _, field := range hostInventoryFields {
+		value, _ := inventory[field].(string)
+		configuredValue, _ := configured[field].(string)
+
+		if mode == "1" && configuredValue == "" {
+			value = ""
+		}
+
+		if value != "" {
+			empty = false
+		}
+
+		fields[field] = value
+	}
+
+	if empty {
+		d.Set("inventory", []interface{}{})
+		return
+	}
+
+	d.Set("inventory", []interface{}{fields})
+}
+
+// readHostProxy sets the proxy of the host the way it is configured, by id or
+// by name, so moving the host to another proxy shows up as a diff.
+func readHostProxy(d *schema.ResourceData, api *zabbix.API, proxyHostID string) error {
+	if proxyHostID == "" || proxyHostID == "0" {
+		d.Set("proxy", "")
+		return nil
+	}
+
+	if d.Get("proxy").(string) == proxyHostID {
+		return nil
+	}
+
+	proxy, err := proxyGetByID(api, proxyHostID)
+
+	if err != nil {
+		return err
+	}
+
+	d.Set("proxy", proxy.Host)
+
+	return nil
+}
+
+// hostObject extends the go-zabbix-api host with the fields it does not support
+// https://www.zabbix.com/documentation/current/manual/api/reference/host/object
+type hostObject struct {
+	zabbix.Host
+	ProxyHostID   string          `json:"proxy_hostid"`
+	Interfaces    []hostInterface `json:"interfaces,omitempty"`
+	InventoryMode string          `json:"inventory_mode,omitempty"`
+	Inventory     interface{}     `json:"inventory,omitempty"`
+	Macros        *[]userMacro    `json:"macros,omitempty"`
+	Tags          *[]hostTag      `json:"tags,omitempty"`
+}
+
+// hostInterface represent Zabbix host interface object, the go-zabbix-api
+// interface does not have its id
+// https://www.zabbix.com/documentation/current/manual/api/reference/hostinterface/object
+type hostInterface struct {
+	InterfaceID string      `json:"interfaceid,omitempty"`
+	HostID      string      `json:"hostid,omitempty"`
+	DNS         string      `json:"dns"`
+	IP          string      `json:"ip"`
+	Main        string      `json:"main"`
+	Port        string      `json:"port"`
+	Type        string      `json:"type"`
+	UseIP       string      `json:"useip"`
+	Details     interface{} `json:"details,omitempty"`
+}
+
+// hostInterfaceDetails represent the details of SNMP interfaces since Zabbix 5.0
+type hostInterfaceDetails struct {
+	Version        string `json:"version"`
+	Bulk           string `json:"bulk"`
+	Community      string `json:"community,omitempty"`
+	SecurityName   string `json:"securityname,omitempty"`
+	SecurityLevel  string `json:"securitylevel,omitempty"`
+	AuthPassphrase string `json:"authpassphrase,omitempty"`
+	PrivPassphrase string `json:"privpassphrase,omitempty"`
+	AuthProtocol   string `json:"authprotocol,omitempty"`
+	PrivProtocol   string `json:"privprotocol,omitempty"`
+	ContextName    string `json:"contextname,omitempty"`
+}
+
+func hostsGet(api *zabbix.API, params zabbix.Params) (res []hostObject, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("host.get", params, &res)
+	return
+}
+
+func hostInterfacesGet(api *zabbix.API, params zabbix.Params) (res []hostInterface, err error) {
+	err = api.CallWithErrorParse("hostinterface.get", params, &res)
+	return
+}
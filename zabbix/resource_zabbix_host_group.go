@@ -1,7 +1,9 @@
 package zabbix
 
 import (
+	"fmt"
 	"log"
+	"strconv"
 	"strings"
 
 	"github.com/claranet/go-zabbix-api"
@@ -15,23 +17,48 @@ func resourceZabbixHostGroup() *schema.Resource {
 		Exists: resourceZabbixHostGroupExists,
 		Update: resourceZabbixHostGroupUpdate,
 		Delete: resourceZabbixHostGroupDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceZabbixHostGroupImport,
+		},
+		CustomizeDiff: resourceZabbixHostGroupCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Name of the host group.",
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Name of the host group, nested groups are named Parent/Child.",
+				ValidateFunc: validateHostGroupName,
 			},
 			"group_id": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: false,
 				Computed: true,
 			},
+			"propagate_permissions": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Apply the permissions of the group to its subgroups, only supported on Zabbix 6.2+.",
+			},
+			"propagate_tag_filters": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Apply the tag filters of the group to its subgroups, only supported on Zabbix 6.2+.",
+			},
 		},
 	}
 }
 
+func validateHostGroupName(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	if strings.HasPrefix(v, "/") || strings.HasSuffix(v, "/") || strings.Contains(v, "//") {
+		errs = append(errs, fmt.Errorf("%q, nested group names must be like Parent/Child, got %s", key, v))
+	}
+	return
+}
+
 func resourceZabbixHostGroupCreate(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	hostGroup := zabbix.HostGroup{
 		Name: d.Get("name").(string),
@@ -50,11 +77,11 @@ func resourceZabbixHostGroupCreate(d *schema.ResourceData, meta interface{}) err
 	d.Set("group_id", groupID)
 	d.SetId(groupID)
 
-	return nil
+	return propagateHostGroup(d, api)
 }
 
 func resourceZabbixHostGroupRead(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	log.Printf("[DEBUG] Will read host group with id %s", d.Id())
 
@@ -65,12 +92,13 @@ func resourceZabbixHostGroupRead(d *schema.ResourceData, meta interface{}) error
 	}
 
 	d.Set("name", group.Name)
+	d.Set("group_id", group.GroupID)
 
 	return nil
 }
 
 func resourceZabbixHostGroupExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	_, err := api.HostGroupGetByID(d.Id())
 	if err != nil {
@@ -84,18 +112,81 @@ func resourceZabbixHostGroupExists(d *schema.ResourceData, meta interface{}) (bo
 }
 
 func resourceZabbixHostGroupUpdate(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	hostGroup := zabbix.HostGroup{
 		Name:    d.Get("name").(string),
 		GroupID: d.Id(),
 	}
 
-	return api.HostGroupsUpdate(zabbix.HostGroups{hostGroup})
+	err := api.HostGroupsUpdate(zabbix.HostGroups{hostGroup})
+	if err != nil {
+		return err
+	}
+
+	return propagateHostGroup(d, api)
 }
 
 func resourceZabbixHostGroupDelete(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	return api.HostGroupsDeleteByIds([]string{d.Id()})
 }
+
+// resourceZabbixHostGroupImport accepts the id or the name of the host group
+func resourceZabbixHostGroupImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	api := meta.(*providerMeta).api
+
+	if _, err := strconv.Atoi(d.Id()); err == nil {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	groups, err := api.HostGroupsGet(zabbix.Params{
+		"output": "extend",
+		"filter": map[string]interface{}{
+			"name": d.Id(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) != 1 {
+		return nil, fmt.Errorf("Expected one host group named %s and got %d host groups", d.Id(), len(groups))
+	}
+	d.SetId(groups[0].GroupID)
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceZabbixHostGroupCustomizeDiff checks the server supports
+// hostgroup.propagate, which was added in Zabbix 6.2, before the group is
+// created or updated.
+func resourceZabbixHostGroupCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.Get("propagate_permissions").(bool) && !d.Get("propagate_tag_filters").(bool) {
+		return nil
+	}
+
+	zabbixVersion := getZabbixServerVersion(meta)
+	if !isZabbixServerVersion62OrHigher(zabbixVersion) {
+		return fmt.Errorf("Propagating host group permissions and tag filters is only supported on Zabbix 6.2+, got %s", zabbixVersion)
+	}
+	return nil
+}
+
+// propagateHostGroup applies the permissions and tag filters of the group to
+// its existing subgroups, the subgroups created later are not affected.
+func propagateHostGroup(d *schema.ResourceData, api *zabbix.API) error {
+	permissions := d.Get("propagate_permissions").(bool)
+	tagFilters := d.Get("propagate_tag_filters").(bool)
+	if !permissions && !tagFilters {
+		return nil
+	}
+
+	_, err := callCreate(api, "hostgroup.propagate", map[string]interface{}{
+		"groups": []map[string]string{
+			{"groupid": d.Id()},
+		},
+		"permissions": permissions,
+		"tag_filters": tagFilters,
+	}, "groupids")
+	return err
+}
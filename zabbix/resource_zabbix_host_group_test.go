@@ -2,6 +2,7 @@ package zabbix
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/claranet/go-zabbix-api"
@@ -28,12 +29,77 @@ func TestAccZabbixHostGroup_Basic(t *testing.T) {
 					resource.TestCheckResourceAttr("zabbix_host_group.zabbix", "name", groupName),
 				),
 			},
+			{
+				ResourceName:      "zabbix_host_group.zabbix",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// propagation is an action, not read back from Zabbix
+				ImportStateVerifyIgnore: []string{"propagate_permissions", "propagate_tag_filters"},
+			},
+			{
+				ResourceName:            "zabbix_host_group.zabbix",
+				ImportState:             true,
+				ImportStateId:           groupName,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"propagate_permissions", "propagate_tag_filters"},
+			},
+		},
+	})
+}
+
+func TestAccZabbixHostGroup_Nested(t *testing.T) {
+	groupName := fmt.Sprintf("host_group_%s", acctest.RandString(5))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixHostGroupDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccZabbixHostGroupConfig(groupName + "/"),
+				ExpectError: regexp.MustCompile("nested group names must be like Parent/Child"),
+			},
+			{
+				Config: testAccZabbixHostGroupConfig(groupName + "/child"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host_group.zabbix", "name", groupName+"/child"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccZabbixHostGroup_Propagate(t *testing.T) {
+	groupName := fmt.Sprintf("host_group_%s", acctest.RandString(5))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckZabbixVersion(t, isZabbixServerVersion62OrHigher) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixHostGroupDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					resource "zabbix_host_group" "zabbix" {
+						name                  = "%s"
+						propagate_permissions = true
+						propagate_tag_filters = true
+					}
+
+					resource "zabbix_host_group" "child" {
+						name = "${zabbix_host_group.zabbix.name}/child"
+					}`, groupName,
+				),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host_group.zabbix", "propagate_permissions", "true"),
+					resource.TestCheckResourceAttr("zabbix_host_group.zabbix", "propagate_tag_filters", "true"),
+				),
+			},
 		},
 	})
 }
 
 func testAccCheckZabbixHostGroupDestroy(s *terraform.State) error {
-	api := testAccProvider.Meta().(*zabbix.API)
+	api := testAccProvider.Meta().(*providerMeta).api
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "zabbix_host_group" {
@@ -70,7 +136,7 @@ func testAccCheckZabbixHostGroupExists(resource string, hostGroup *zabbix.HostGr
 			return fmt.Errorf("No record ID set")
 		}
 
-		api := testAccProvider.Meta().(*zabbix.API)
+		api := testAccProvider.Meta().(*providerMeta).api
 		group, err := api.HostGroupGetByID(rs.Primary.ID)
 		if err != nil {
 			return err
@@ -2,6 +2,7 @@ package zabbix
 
 import (
 	"fmt"
+	"log"
 	"testing"
 
 	"github.com/claranet/go-zabbix-api"
@@ -40,7 +41,7 @@ func TestAccZabbixHost_Basic(t *testing.T) {
 }
 
 func testAccCheckZabbixHostDestroy(s *terraform.State) error {
-	api := testAccProvider.Meta().(*zabbix.API)
+	api := testAccProvider.Meta().(*providerMeta).api
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "zabbix_host" {
@@ -87,7 +88,7 @@ func testAccCheckZabbixHostExists(resource string, host *zabbix.Host) resource.T
 			return fmt.Errorf("No record ID id set")
 		}
 
-		api := testAccProvider.Meta().(*zabbix.API)
+		api := testAccProvider.Meta().(*providerMeta).api
 		getHost, err := api.HostGetByID(rs.Primary.ID)
 		if err != nil {
 			return err
@@ -99,7 +100,7 @@ func testAccCheckZabbixHostExists(resource string, host *zabbix.Host) resource.T
 
 func testAccCheckZabbixHostAttributes(host *zabbix.Host, want zabbix.Host, groupNames []string, templateNames []string) resource.TestCheckFunc {
 	return func(state *terraform.State) error {
-		api := testAccProvider.Meta().(*zabbix.API)
+		api := testAccProvider.Meta().(*providerMeta).api
 
 		if host.Host != want.Host {
 			return fmt.Errorf("Got host name: %q, expected: %q", host.Host, want.Host)
@@ -157,3 +158,538 @@ func containTemplate(templateNames zabbix.Templates, name string) bool {
 	}
 	return false
 }
+
+func TestAccZabbixHost_Proxy(t *testing.T) {
+	strID := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixHostDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixHostProxyConfig(strID, "zabbix_proxy.first.name"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "proxy", fmt.Sprintf("first_proxy_%s", strID)),
+				),
+			},
+			{
+				Config: testAccZabbixHostProxyConfig(strID, "zabbix_proxy.second.id"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("zabbix_host.zabbix", "proxy", "zabbix_proxy.second", "id"),
+				),
+			},
+			{
+				Config: testAccZabbixHostProxyConfig(strID, `""`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "proxy", ""),
+				),
+			},
+		},
+	})
+}
+
+func testAccZabbixHostProxyConfig(strID string, proxy string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_proxy" "first" {
+			name = "first_proxy_%s"
+		}
+
+		resource "zabbix_proxy" "second" {
+			name = "second_proxy_%s"
+		}
+
+		resource "zabbix_host_group" "zabbix" {
+			name = "host_group_%s"
+		}
+
+		resource "zabbix_host" "zabbix" {
+			host   = "host_%s"
+			groups = [zabbix_host_group.zabbix.name]
+			proxy  = %s
+			interfaces {
+				ip   = "127.0.0.1"
+				main = true
+			}
+		}
+	`, strID, strID, strID, strID, proxy)
+}
+
+func TestAccZabbixHost_GroupID(t *testing.T) {
+	strID := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixHostDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixHostGroupIDConfig(strID, "host_group"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "groups.#", "2"),
+				),
+			},
+			{
+				// the host references the nested group by id and is left
+				// unchanged by its rename
+				Config: testAccZabbixHostGroupIDConfig(strID, "renamed_host_group"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host_group.child", "name", fmt.Sprintf("renamed_host_group_%s/child", strID)),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "groups.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccZabbixHostGroupIDConfig(strID string, groupName string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "parent" {
+			name = "%s_%s"
+		}
+
+		resource "zabbix_host_group" "child" {
+			name = "${zabbix_host_group.parent.name}/child"
+		}
+
+		resource "zabbix_host" "zabbix" {
+			host   = "host_%s"
+			groups = [zabbix_host_group.parent.name, zabbix_host_group.child.group_id]
+			interfaces {
+				ip   = "127.0.0.1"
+				main = true
+			}
+		}
+	`, groupName, strID, strID)
+}
+
+func TestAccZabbixHost_InterfaceUpdate(t *testing.T) {
+	var interfaceID string
+	strID := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixHostDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixHostInterfacesConfig(strID, `
+					interfaces {
+						ip   = "127.0.0.1"
+						main = true
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.#", "1"),
+					testAccCheckZabbixHostInterfaceID("zabbix_host.zabbix", 0, &interfaceID, false),
+				),
+			},
+			{
+				Config: testAccZabbixHostInterfacesConfig(strID, `
+					interfaces {
+						ip   = "127.0.0.2"
+						port = "10060"
+						main = true
+					}
+					interfaces {
+						dns  = "localhost"
+						type = "jmx"
+						port = "12345"
+						main = true
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.#", "2"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.0.ip", "127.0.0.2"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.0.port", "10060"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.1.type", "jmx"),
+					testAccCheckZabbixHostInterfaceID("zabbix_host.zabbix", 0, &interfaceID, true),
+				),
+			},
+			{
+				Config: testAccZabbixHostInterfacesConfig(strID, `
+					interfaces {
+						ip   = "127.0.0.2"
+						port = "10060"
+						main = true
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.#", "1"),
+					testAccCheckZabbixHostInterfaceID("zabbix_host.zabbix", 0, &interfaceID, true),
+				),
+			},
+			{
+				Config: testAccZabbixHostInterfacesConfig(strID, `
+					interfaces {
+						ip   = "127.0.0.2"
+						port = "10060"
+						main = true
+					}
+					interfaces {
+						ip   = "127.0.0.3"
+						main = false
+					}
+				`) + testAccZabbixHostInterfaceItemConfig(1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.#", "2"),
+					testAccCheckZabbixHostInterfaceID("zabbix_host.zabbix", 1, &interfaceID, false),
+					resource.TestCheckResourceAttrPair("zabbix_item.zabbix", "interface_id", "zabbix_host.zabbix", "interfaces.1.interface_id"),
+				),
+			},
+			{
+				Config: testAccZabbixHostInterfacesConfig(strID, `
+					interfaces {
+						ip   = "127.0.0.3"
+						main = true
+					}
+				`) + testAccZabbixHostInterfaceItemConfig(0),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.0.ip", "127.0.0.3"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.0.main", "true"),
+					testAccCheckZabbixHostInterfaceID("zabbix_host.zabbix", 0, &interfaceID, true),
+					resource.TestCheckResourceAttrPair("zabbix_item.zabbix", "interface_id", "zabbix_host.zabbix", "interfaces.0.interface_id"),
+				),
+			},
+		},
+	})
+}
+
+// testAccZabbixHostInterfaceItemConfig binds an item to an interface of the
+// host, its id must not change when the other interfaces are removed.
+func testAccZabbixHostInterfaceItemConfig(index int) string {
+	return fmt.Sprintf(`
+		resource "zabbix_item" "zabbix" {
+			name         = "item_test"
+			key          = "system.cpu.num"
+			delay        = "60"
+			value_type   = "unsigned"
+			host_id      = zabbix_host.zabbix.id
+			interface_id = zabbix_host.zabbix.interfaces.%d.interface_id
+		}
+	`, index)
+}
+
+// testAccCheckZabbixHostInterfaceID saves the id of an interface, or checks it
+// did not change when same is true.
+func testAccCheckZabbixHostInterfaceID(resource string, index int, interfaceID *string, same bool) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resource)
+		}
+
+		id := rs.Primary.Attributes[fmt.Sprintf("interfaces.%d.interface_id", index)]
+		if id == "" {
+			return fmt.Errorf("No interface ID set")
+		}
+		if same && id != *interfaceID {
+			return fmt.Errorf("Interface was recreated, got id %s, expected %s", id, *interfaceID)
+		}
+		*interfaceID = id
+		return nil
+	}
+}
+
+func testAccZabbixHostInterfacesConfig(strID string, interfaces string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "zabbix" {
+			name = "host_group_%s"
+		}
+
+		resource "zabbix_host" "zabbix" {
+			host   = "host_%s"
+			groups = [zabbix_host_group.zabbix.name]
+			%s
+		}
+	`, strID, strID, interfaces)
+}
+
+func TestAccZabbixHost_SNMPInterface(t *testing.T) {
+	strID := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixHostDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixHostInterfacesConfig(strID, `
+					interfaces {
+						ip   = "127.0.0.1"
+						type = "snmp"
+						port = "161"
+						main = true
+						snmp {
+							version   = 2
+							community = "public"
+						}
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.0.snmp.0.version", "2"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.0.snmp.0.community", "public"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.0.snmp.0.bulk", "true"),
+				),
+			},
+			{
+				Config: testAccZabbixHostInterfacesConfig(strID, `
+					interfaces {
+						ip   = "127.0.0.1"
+						type = "snmp"
+						port = "161"
+						main = true
+						snmp {
+							version         = 3
+							bulk            = false
+							security_name   = "monitoring"
+							security_level  = "authpriv"
+							auth_protocol   = "sha1"
+							auth_passphrase = "auth_secret"
+							priv_protocol   = "aes128"
+							priv_passphrase = "priv_secret"
+						}
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.0.snmp.0.version", "3"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.0.snmp.0.bulk", "false"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.0.snmp.0.security_name", "monitoring"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.0.snmp.0.security_level", "authpriv"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.0.snmp.0.auth_protocol", "sha1"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.0.snmp.0.priv_protocol", "aes128"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccZabbixHost_SNMPv3Interface(t *testing.T) {
+	strID := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixHostDestroy,
+		Steps: []resource.TestStep{
+			{
+				// the passphrases of a new host are kept from the configuration,
+				// the plan is empty after the apply
+				Config: testAccZabbixHostInterfacesConfig(strID, `
+					interfaces {
+						ip   = "127.0.0.1"
+						main = true
+					}
+					interfaces {
+						ip   = "127.0.0.1"
+						type = "snmp"
+						port = "161"
+						main = true
+						snmp {
+							version         = 3
+							security_name   = "monitoring"
+							security_level  = "authpriv"
+							auth_passphrase = "auth_secret"
+							priv_passphrase = "priv_secret"
+						}
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.1.snmp.0.auth_passphrase", "auth_secret"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "interfaces.1.snmp.0.priv_passphrase", "priv_secret"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccZabbixHost_Inventory(t *testing.T) {
+	strID := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixHostDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixHostInterfacesConfig(strID, `
+					interfaces {
+						ip   = "127.0.0.1"
+						main = true
+					}
+					inventory_mode = "manual"
+					inventory {
+						location   = "Paris"
+						serialno_a = "ABC123"
+						contact    = "ops@example.com"
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "inventory_mode", "manual"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "inventory.0.location", "Paris"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "inventory.0.serialno_a", "ABC123"),
+				),
+			},
+			{
+				Config: testAccZabbixHostInterfacesConfig(strID, `
+					interfaces {
+						ip   = "127.0.0.1"
+						main = true
+					}
+					inventory_mode = "automatic"
+					inventory {
+						location = "Lyon"
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "inventory_mode", "automatic"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "inventory.0.location", "Lyon"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "inventory.0.serialno_a", ""),
+				),
+			},
+			{
+				Config: testAccZabbixHostInterfacesConfig(strID, `
+					interfaces {
+						ip   = "127.0.0.1"
+						main = true
+					}
+					inventory_mode = "disabled"
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "inventory_mode", "disabled"),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "inventory.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccZabbixHost_UserMacro(t *testing.T) {
+	strID := acctest.RandString(5)
+	var hostID string
+	interfaces := `
+		interfaces {
+			ip   = "127.0.0.1"
+			main = true
+		}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixHostDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixHostInterfacesConfig(strID, interfaces),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckResourceID("zabbix_host.zabbix", &hostID),
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "user_macro.#", "0"),
+				),
+			},
+			{
+				// the macros of hosts without user_macro are not managed
+				PreConfig: testAccZabbixHostCreateMacro(&hostID),
+				Config:    testAccZabbixHostInterfacesConfig(strID, interfaces),
+				PlanOnly:  true,
+			},
+			{
+				Config: testAccZabbixHostInterfacesConfig(strID, `
+					interfaces {
+						ip   = "127.0.0.1"
+						main = true
+					}
+					user_macro {
+						name  = "SNMP_COMMUNITY"
+						value = "private"
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "user_macro.#", "1"),
+				),
+			},
+			{
+				Config: testAccZabbixHostInterfacesConfig(strID, `
+					interfaces {
+						ip   = "127.0.0.1"
+						main = true
+					}
+					user_macro {
+						name  = "SNMP_COMMUNITY"
+						value = "private"
+					}
+					user_macro {
+						name        = "{$DB_PASSWORD}"
+						value       = "secret_value"
+						description = "Password of the monitoring user"
+						type        = "secret"
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "user_macro.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+// testAccZabbixHostCreateMacro adds a macro to the host outside of terraform
+func testAccZabbixHostCreateMacro(hostID *string) func() {
+	return func() {
+		api := testAccProvider.Meta().(*providerMeta).api
+
+		_, err := callCreate(api, "usermacro.create", map[string]interface{}{
+			"hostid": *hostID,
+			"macro":  "{$UNMANAGED}",
+			"value":  "1",
+		}, "hostmacroids")
+		if err != nil {
+			log.Print(err)
+		}
+	}
+}
+
+func TestAccZabbixHost_Tags(t *testing.T) {
+	strID := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixHostDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixHostInterfacesConfig(strID, `
+					interfaces {
+						ip   = "127.0.0.1"
+						main = true
+					}
+					tag {
+						tag   = "env"
+						value = "staging"
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "tag.#", "1"),
+				),
+			},
+			{
+				Config: testAccZabbixHostInterfacesConfig(strID, `
+					interfaces {
+						ip   = "127.0.0.1"
+						main = true
+					}
+					tag {
+						tag   = "env"
+						value = "production"
+					}
+					tag {
+						tag = "critical"
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.zabbix", "tag.#", "2"),
+				),
+			},
+		},
+	})
+}
@@ -3,6 +3,7 @@ package zabbix
 import (
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 
 	"github.com/claranet/go-zabbix-api"
@@ -19,6 +20,7 @@ func resourceZabbixItemPrototype() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		CustomizeDiff: customizeDiffItemPrototype,
 		Schema: map[string]*schema.Schema{
 			"delay": &schema.Schema{
 				Type:     schema.TypeString,
@@ -45,28 +47,20 @@ func resourceZabbixItemPrototype() *schema.Resource {
 				Description: "Name of the item prototype.",
 			},
 			"type": &schema.Schema{
-				Type:     schema.TypeInt,
-				Optional: true,
-				Default:  0,
-				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
-					v := val.(int)
-					if v < 0 || v > 16 {
-						errs = append(errs, fmt.Errorf("%q, must be between 0 and 16 inclusive, got %d", key, v))
-					}
-					return
-				},
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "zabbix_agent",
+				Description:  "Type of the item, its name or its value.",
+				ValidateFunc: validateEnum(ItemTypes),
+				StateFunc:    enumStateFunc(ItemTypes),
 			},
 			"value_type": &schema.Schema{
-				Type:     schema.TypeInt,
-				Optional: true,
-				Default:  0,
-				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
-					v := val.(int)
-					if v < 0 || v > 4 {
-						errs = append(errs, fmt.Errorf("%q, must be between 0 and 4 inclusive, got %d", key, v))
-					}
-					return
-				},
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "float",
+				Description:  "Type of information of the item, its name or its value.",
+				ValidateFunc: validateEnum(ItemValueTypes),
+				StateFunc:    enumStateFunc(ItemValueTypes),
 			},
 			"rule_id": &schema.Schema{
 				Type:     schema.TypeString,
@@ -122,41 +116,151 @@ func resourceZabbixItemPrototype() *schema.Resource {
 				Description: "Allowed hosts. Used only by trapper items.",
 			},
 			"status": &schema.Schema{
-				Type:        schema.TypeInt,
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "enabled",
+				Description:  "Status of the item prototype, its name or its value.",
+				ValidateFunc: validateEnum(ItemStatuses),
+				StateFunc:    enumStateFunc(ItemStatuses),
+			},
+			"preprocessing": &schema.Schema{
+				Type:        schema.TypeList,
+				Elem:        schemaItemPreprocessing(),
+				Optional:    true,
+				Description: "Ordered preprocessing steps of the item prototype, only supported on Zabbix 3.4+.",
+			},
+			"master_item_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "ID of the master item or item prototype of dependent item prototypes, only supported on Zabbix 3.4+.",
+			},
+			"http_agent": &schema.Schema{
+				Type:        schema.TypeList,
+				Elem:        schemaItemHTTPAgent(),
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Request of HTTP agent item prototypes, only supported on Zabbix 4.0+.",
+			},
+			"applications": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
 				Optional:    true,
-				Default:     "0",
-				Description: "Status of the item.",
+				Description: "IDs of the applications of the item prototype, only supported before Zabbix 5.4.",
+			},
+			"application_prototypes": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "Names of the application prototypes of the item prototype, with LLD macros, only supported before Zabbix 5.4.",
+			},
+			"tag": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        schemaHostTag(),
+				Optional:    true,
+				Description: "Tags of the item prototype, with LLD macros, only supported on Zabbix 5.4+.",
 			},
 		},
 	}
 }
 
-func createItemPrototypeObject(d *schema.ResourceData, api *zabbix.API) (*zabbix.ItemPrototype, error) {
-
-	item := zabbix.ItemPrototype{
-		Delay:        d.Get("delay").(string),
-		HostID:       d.Get("host_id").(string),
-		InterfaceID:  d.Get("interface_id").(string),
-		Key:          d.Get("key").(string),
-		Name:         d.Get("name").(string),
-		Type:         zabbix.ItemType(d.Get("type").(int)),
-		ValueType:    zabbix.ValueType(d.Get("value_type").(int)),
-		RuleID:       d.Get("rule_id").(string),
-		DataType:     zabbix.DataType(d.Get("data_type").(int)),
-		Delta:        zabbix.DeltaType(d.Get("delta").(int)),
-		Description:  d.Get("description").(string),
-		History:      d.Get("history").(string),
-		Trends:       d.Get("trends").(string),
-		TrapperHosts: d.Get("trapper_host").(string),
-		Status:       d.Get("status").(int),
+func customizeDiffItemPrototype(d *schema.ResourceDiff, meta interface{}) error {
+	err := customizeDiffDependentItem(d)
+	if err != nil {
+		return err
 	}
+	err = customizeDiffHTTPAgent(d)
+	if err != nil {
+		return err
+	}
+	return customizeDiffItemGrouping(d, getZabbixServerVersion(meta), "applications", "application_prototypes")
+}
+
+// createApplicationPrototypes returns the application prototypes of item
+// prototypes.
+func createApplicationPrototypes(d *schema.ResourceData, zabbixVersion string) (*[]applicationPrototype, error) {
+	names := getStringSet(d, "application_prototypes")
+	if isZabbixServerVersion54OrHigher(zabbixVersion) {
+		if len(names) > 0 {
+			return nil, fmt.Errorf("application_prototypes are not supported from Zabbix 5.4")
+		}
+		return nil, nil
+	}
+
+	applicationPrototypes := make([]applicationPrototype, len(names))
+	for i, name := range names {
+		applicationPrototypes[i] = applicationPrototype{Name: name}
+	}
+	return &applicationPrototypes, nil
+}
+
+func readApplicationPrototypes(applicationPrototypes []applicationPrototype) []string {
+	names := make([]string, len(applicationPrototypes))
+	for i, applicationPrototype := range applicationPrototypes {
+		names[i] = applicationPrototype.Name
+	}
+	return names
+}
+
+func createItemPrototypeObject(d *schema.ResourceData, zabbixVersion string) (*itemPrototypeObject, error) {
+
+	item := itemPrototypeObject{
+		ItemPrototype: zabbix.ItemPrototype{
+			Delay:        d.Get("delay").(string),
+			HostID:       d.Get("host_id").(string),
+			InterfaceID:  d.Get("interface_id").(string),
+			Key:          d.Get("key").(string),
+			Name:         d.Get("name").(string),
+			Type:         zabbix.ItemType(enumToInt(ItemTypes, d.Get("type").(string))),
+			ValueType:    zabbix.ValueType(enumToInt(ItemValueTypes, d.Get("value_type").(string))),
+			RuleID:       d.Get("rule_id").(string),
+			DataType:     zabbix.DataType(d.Get("data_type").(int)),
+			Delta:        zabbix.DeltaType(d.Get("delta").(int)),
+			Description:  d.Get("description").(string),
+			History:      d.Get("history").(string),
+			Trends:       d.Get("trends").(string),
+			TrapperHosts: d.Get("trapper_host").(string),
+			Status:       enumToInt(ItemStatuses, d.Get("status").(string)),
+		},
+	}
+
+	preprocessing, err := createItemPreprocessing(d, zabbixVersion)
+	if err != nil {
+		return nil, err
+	}
+	item.Preprocessing = preprocessing
+
+	item.MasterItemID, err = createMasterItemID(d, zabbixVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	httpAgent, httpAuth, err := createItemHTTPAgent(d, zabbixVersion)
+	if err != nil {
+		return nil, err
+	}
+	item.itemHTTPAgent = *httpAgent
+	item.AuthType = httpAuth.AuthType
+	item.Username = httpAuth.Username
+	item.Password = httpAuth.Password
+
+	item.Applications, err = createItemApplications(d, zabbixVersion)
+	if err != nil {
+		return nil, err
+	}
+	item.ApplicationPrototypes, err = createApplicationPrototypes(d, zabbixVersion)
+	if err != nil {
+		return nil, err
+	}
+	item.Tags, err = createItemTags(d, zabbixVersion)
+	if err != nil {
+		return nil, err
+	}
+
 	return &item, nil
 }
 
 func resourceZabbixItemPrototypeCreate(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
-
-	item, err := createItemPrototypeObject(d, api)
+	item, err := createItemPrototypeObject(d, getZabbixServerVersion(meta))
 	if err != nil {
 		return err
 	}
@@ -165,13 +269,23 @@ func resourceZabbixItemPrototypeCreate(d *schema.ResourceData, meta interface{})
 }
 
 func resourceZabbixItemPrototypeRead(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
-	items, err := api.ItemPrototypesGet(zabbix.Params{
+	zabbixVersion := getZabbixServerVersion(meta)
+	params := zabbix.Params{
 		"itemids":             d.Id(),
 		"output":              "extend",
 		"selectDiscoveryRule": "extend",
-	})
+	}
+	if isZabbixServerVersion34OrHigher(zabbixVersion) {
+		params["selectPreprocessing"] = "extend"
+	}
+	if isZabbixServerVersion54OrHigher(zabbixVersion) {
+		params["selectTags"] = "extend"
+	} else {
+		params["selectApplicationPrototypes"] = "extend"
+	}
+	items, err := itemPrototypesGet(api, params)
 	if err != nil {
 		return err
 	}
@@ -185,8 +299,8 @@ func resourceZabbixItemPrototypeRead(d *schema.ResourceData, meta interface{}) e
 	d.Set("interface_id", item.InterfaceID)
 	d.Set("key", item.Key)
 	d.Set("name", item.Name)
-	d.Set("type", item.Type)
-	d.Set("value_type", item.ValueType)
+	d.Set("type", strconv.Itoa(int(item.Type)))
+	d.Set("value_type", strconv.Itoa(int(item.ValueType)))
 	d.Set("rule_id", item.DiscoveryRule.ItemID)
 	d.Set("data_type", item.DataType)
 	d.Set("delta", item.Delta)
@@ -194,14 +308,29 @@ func resourceZabbixItemPrototypeRead(d *schema.ResourceData, meta interface{}) e
 	d.Set("history", item.History)
 	d.Set("trends", item.Trends)
 	d.Set("trapper_host", item.TrapperHosts)
-	d.Set("status", item.Status)
+	d.Set("status", strconv.Itoa(item.Status))
+	if item.Preprocessing != nil {
+		d.Set("preprocessing", readItemPreprocessing(d, *item.Preprocessing))
+	}
+	readDependentItem(d, item.Type, item.MasterItemID)
+	d.Set("http_agent", readItemHTTPAgent(d, item.Type, item.itemHTTPAgent, itemHTTPAuth{
+		AuthType: item.AuthType,
+		Username: item.Username,
+	}))
+	if item.ApplicationPrototypes != nil {
+		d.Set("application_prototypes", readApplicationPrototypes(*item.ApplicationPrototypes))
+	}
+	err = readItemGrouping(d, api, "itemprototype.get", zabbixVersion, item.Tags)
+	if err != nil {
+		return err
+	}
 
 	log.Printf("[DEBUG] Item prototype name is %s\n", item.Name)
 	return nil
 }
 
 func resourceZabbixItemPrototypeExist(d *schema.ResourceData, meta interface{}) (bool, error) {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	_, err := api.ItemPrototypeGetByID(d.Id())
 	if err != nil {
@@ -215,22 +344,20 @@ func resourceZabbixItemPrototypeExist(d *schema.ResourceData, meta interface{})
 }
 
 func resourceZabbixItemPrototypeUpdate(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
-
-	item, err := createItemPrototypeObject(d, api)
+	item, err := createItemPrototypeObject(d, getZabbixServerVersion(meta))
 	if err != nil {
 		return err
 	}
 
 	item.ItemID = d.Id()
-	log.Printf("[DEBUG] Update item prototype %#v", item)
+	log.Printf("[DEBUG] Update item prototype %s (%s)", item.ItemID, item.Name)
 	return createRetry(d, meta, updateItemPrototype, *item, resourceZabbixItemPrototypeRead)
 }
 
 func resourceZabbixItemPrototypeDelete(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
-	return deleteRetry(d.Id(), getItemPrototypeParentID, api.ItemPrototypesDeleteIDs, api)
+	return deleteItemWithDependents(api, d.Id(), "itemprototype.get", getItemPrototypeParentID, api.ItemPrototypesDeleteIDs)
 }
 
 func getItemPrototypeParentID(api *zabbix.API, id string) (string, error) {
@@ -252,23 +379,54 @@ func getItemPrototypeParentID(api *zabbix.API, id string) (string, error) {
 }
 
 func createItemPrototype(item interface{}, api *zabbix.API) (id string, err error) {
-	items := zabbix.ItemPrototypes{item.(zabbix.ItemPrototype)}
-
-	err = api.ItemPrototypesCreate(items)
+	ids, err := callCreate(api, "itemprototype.create", []itemPrototypeObject{item.(itemPrototypeObject)}, "itemids")
 	if err != nil {
 		return
 	}
-	id = items[0].ItemID
+	id = ids[0]
 	return
 }
 
 func updateItemPrototype(item interface{}, api *zabbix.API) (id string, err error) {
-	items := zabbix.ItemPrototypes{item.(zabbix.ItemPrototype)}
-
-	err = api.ItemPrototypesUpdate(items)
+	ids, err := callCreate(api, "itemprototype.update", []itemPrototypeObject{item.(itemPrototypeObject)}, "itemids")
 	if err != nil {
 		return
 	}
-	id = items[0].ItemID
+	id = ids[0]
+	return
+}
+
+// itemPrototypeObject extends the go-zabbix-api item prototype with the fields
+// it does not support
+// https://www.zabbix.com/documentation/current/manual/api/reference/itemprototype/object
+type itemPrototypeObject struct {
+	zabbix.ItemPrototype
+	Preprocessing *[]itemPreprocessing `json:"preprocessing,omitempty"`
+	MasterItemID  string               `json:"master_itemid,omitempty"`
+	// Applications and ApplicationPrototypes are only supported before
+	// Zabbix 5.4, Tags from Zabbix 5.4
+	Applications          *[]string               `json:"applications,omitempty"`
+	ApplicationPrototypes *[]applicationPrototype `json:"applicationPrototypes,omitempty"`
+	Tags                  *[]hostTag              `json:"tags,omitempty"`
+	itemHTTPAgent
+	// AuthType, Username and Password replace the go-zabbix-api fields, which
+	// are not sent once cleared
+	AuthType *string `json:"authtype,omitempty"`
+	Username *string `json:"username,omitempty"`
+	Password *string `json:"password,omitempty"`
+}
+
+// applicationPrototype represent Zabbix application prototype object of item
+// prototypes
+// https://www.zabbix.com/documentation/5.2/manual/api/reference/itemprototype/object#application-prototype
+type applicationPrototype struct {
+	Name string `json:"name"`
+}
+
+func itemPrototypesGet(api *zabbix.API, params zabbix.Params) (res []itemPrototypeObject, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("itemprototype.get", params, &res)
 	return
 }
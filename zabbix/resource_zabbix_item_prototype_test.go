@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/claranet/go-zabbix-api"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
@@ -46,8 +45,39 @@ func TestAccZabbixItemPrototype_Basic(t *testing.T) {
 	})
 }
 
+func TestAccZabbixItemPrototype_Grouping(t *testing.T) {
+	strID := acctest.RandString(5)
+	groupName := fmt.Sprintf("host_group_%s", strID)
+	templateName := fmt.Sprintf("template_%s", strID)
+
+	// application prototypes before Zabbix 5.4, tags from Zabbix 5.4
+	countFunc := func(isSupported func(string) bool) func(string) string {
+		return func(zabbixVersion string) string {
+			if isSupported(zabbixVersion) {
+				return "1"
+			}
+			return "0"
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixItemPrototypeDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixItemPrototypeGroupingConfig(groupName, templateName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckResourceAttrValueFunc("zabbix_item_prototype.item_prototype_test", "application_prototypes.#", countFunc(isZabbixServerVersionBefore54)),
+					testCheckResourceAttrValueFunc("zabbix_item_prototype.item_prototype_test", "tag.#", countFunc(isZabbixServerVersion54OrHigher)),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckZabbixItemPrototypeDestroy(s *terraform.State) error {
-	api := testAccProvider.Meta().(*zabbix.API)
+	api := testAccProvider.Meta().(*providerMeta).api
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "zabbix_item_prototype" {
@@ -148,3 +178,53 @@ func testAccZabbixItemPrototypeUpdateConfig(groupName, templateName string) stri
 		}
 	`, groupName, templateName, templateName)
 }
+
+func testAccZabbixItemPrototypeGroupingConfig(groupName, templateName string) string {
+	return fmt.Sprintf(`
+		data "zabbix_server" "compare_to_5_4_0" {
+			compare_version = "5.4.0"
+		}
+
+		resource "zabbix_host_group" "zabbix" {
+			name = "host group test %s"
+		}
+
+		resource "zabbix_template" "template_test" {
+			host = "%s"
+			groups = ["${zabbix_host_group.zabbix.name}"]
+		}
+
+		resource "zabbix_lld_rule" "lld_rule_test" {
+			delay = 60
+			host_id = zabbix_template.template_test.id
+			key = "key.lolo"
+			name = "test_low_level_discovery_rule"
+			type = "zabbix_agent"
+			filter {
+				condition {
+					macro = "{#TESTMACRO}"
+					value = "^lo$"
+				}
+				eval_type = 0
+			}
+		}
+
+		resource "zabbix_item_prototype" "item_prototype_test" {
+			delay = 60
+			host_id  = zabbix_template.template_test.id
+			rule_id = zabbix_lld_rule.lld_rule_test.id
+			key = "test.key[{#TESTMACRO}]"
+			name = "item_prototype_test"
+
+			application_prototypes = data.zabbix_server.compare_to_5_4_0.server_version_ge ? [] : ["Interface {#TESTMACRO}"]
+
+			dynamic "tag" {
+				for_each = data.zabbix_server.compare_to_5_4_0.server_version_ge ? ["{#TESTMACRO}"] : []
+				content {
+					tag   = "interface"
+					value = tag.value
+				}
+			}
+		}
+	`, groupName, templateName)
+}
@@ -2,9 +2,9 @@ package zabbix
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
-	"github.com/claranet/go-zabbix-api"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
@@ -57,6 +57,254 @@ func TestAccZabbixItem_Basic(t *testing.T) {
 	})
 }
 
+func TestAccZabbixItem_Preprocessing(t *testing.T) {
+	strID := acctest.RandString(5)
+	groupName := fmt.Sprintf("host_group_%s", strID)
+	templateName := fmt.Sprintf("template_%s", strID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixItemDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixItemPreprocessingConfig(groupName, templateName, `
+					preprocessing {
+						type   = "jsonpath"
+						params = ["$.stats.requests"]
+					}
+					preprocessing {
+						type = "10"
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccZabbixItemExists("zabbix_item.my_item1"),
+					resource.TestCheckResourceAttr("zabbix_item.my_item1", "preprocessing.#", "2"),
+					resource.TestCheckResourceAttr("zabbix_item.my_item1", "preprocessing.0.type", "jsonpath"),
+					resource.TestCheckResourceAttr("zabbix_item.my_item1", "preprocessing.0.params.0", "$.stats.requests"),
+					resource.TestCheckResourceAttr("zabbix_item.my_item1", "preprocessing.1.type", "10"),
+				),
+			},
+			{
+				Config: testAccZabbixItemPreprocessingConfig(groupName, templateName, `
+					preprocessing {
+						type                 = "regex"
+						params               = ["requests: ([0-9]+)", "\\1"]
+						error_handler        = "set_value"
+						error_handler_params = "0"
+					}
+					preprocessing {
+						type   = "multiplier"
+						params = ["8"]
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_item.my_item1", "preprocessing.#", "2"),
+					resource.TestCheckResourceAttr("zabbix_item.my_item1", "preprocessing.0.type", "regex"),
+					resource.TestCheckResourceAttr("zabbix_item.my_item1", "preprocessing.0.params.#", "2"),
+					resource.TestCheckResourceAttr("zabbix_item.my_item1", "preprocessing.0.error_handler", "set_value"),
+					resource.TestCheckResourceAttr("zabbix_item.my_item1", "preprocessing.1.type", "multiplier"),
+				),
+			},
+			{
+				Config: testAccZabbixItemPreprocessingConfig(groupName, templateName, ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_item.my_item1", "preprocessing.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccZabbixItem_Dependent(t *testing.T) {
+	strID := acctest.RandString(5)
+	groupName := fmt.Sprintf("host_group_%s", strID)
+	templateName := fmt.Sprintf("template_%s", strID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixItemDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixItemDependentConfig(groupName, templateName, ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccZabbixItemExists("zabbix_item.dependent"),
+					resource.TestCheckResourceAttr("zabbix_item.dependent", "type", "18"),
+					resource.TestCheckResourceAttr("zabbix_item.dependent", "delay", ""),
+					resource.TestCheckResourceAttrPair("zabbix_item.dependent", "master_item_id", "zabbix_item.my_item1", "id"),
+				),
+			},
+			{
+				Config:      testAccZabbixItemDependentConfig(groupName, templateName, `delay = "30"`),
+				ExpectError: regexp.MustCompile("delay can not be set on dependent items"),
+			},
+			{
+				ResourceName:      "zabbix_item.dependent",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccZabbixItem_HTTPAgent(t *testing.T) {
+	strID := acctest.RandString(5)
+	groupName := fmt.Sprintf("host_group_%s", strID)
+	templateName := fmt.Sprintf("template_%s", strID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixItemDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixItemHTTPAgentConfig(groupName, templateName, `
+					url = "http://127.0.0.1/status"
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccZabbixItemExists("zabbix_item.http"),
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.request_method", "get"),
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.timeout", "3s"),
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.follow_redirects", "true"),
+				),
+			},
+			{
+				Config: testAccZabbixItemHTTPAgentConfig(groupName, templateName, `
+					url            = "http://127.0.0.1/api/status"
+					request_method = "post"
+					body_type      = "json"
+					body           = "{\"full\": true}"
+					timeout        = "10s"
+					status_codes   = "200,201"
+					retrieve_mode  = "both"
+					auth_type      = "basic"
+					username       = "monitoring"
+					password       = "secret"
+					proxy          = "http://127.0.0.1:3128"
+					headers = {
+						Accept = "application/json"
+					}
+					query_field {
+						name  = "format"
+						value = "json"
+					}
+					query_field {
+						name  = "verbose"
+						value = "1"
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.url", "http://127.0.0.1/api/status"),
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.request_method", "post"),
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.body_type", "json"),
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.auth_type", "basic"),
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.headers.Accept", "application/json"),
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.query_field.#", "2"),
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.query_field.1.name", "verbose"),
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.proxy", "http://127.0.0.1:3128"),
+				),
+			},
+			{
+				ResourceName:            "zabbix_item.http",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"http_agent.0.password"},
+			},
+			{
+				Config: testAccZabbixItemHTTPAgentConfig(groupName, templateName, `
+					url            = "http://127.0.0.1/api/status"
+					request_method = "post"
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.body", ""),
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.proxy", ""),
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.auth_type", "none"),
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.username", ""),
+					resource.TestCheckResourceAttr("zabbix_item.http", "http_agent.0.query_field.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccZabbixItem_Applications(t *testing.T) {
+	strID := acctest.RandString(5)
+	groupName := fmt.Sprintf("host_group_%s", strID)
+	templateName := fmt.Sprintf("template_%s", strID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckZabbixVersion(t, isZabbixServerVersionBefore54) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixItemDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixItemGroupingConfig(groupName, templateName, `
+					applications = [zabbix_application.application_test.id]
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_item.my_item1", "applications.#", "1"),
+				),
+			},
+			{
+				Config: testAccZabbixItemGroupingConfig(groupName, templateName, ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_item.my_item1", "applications.#", "0"),
+				),
+			},
+			{
+				Config: testAccZabbixItemGroupingConfig(groupName, templateName, `
+					tag {
+						tag = "component"
+					}
+				`),
+				ExpectError: regexp.MustCompile("tag is only supported from Zabbix 5.4"),
+			},
+		},
+	})
+}
+
+func TestAccZabbixItem_Tags(t *testing.T) {
+	strID := acctest.RandString(5)
+	groupName := fmt.Sprintf("host_group_%s", strID)
+	templateName := fmt.Sprintf("template_%s", strID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckZabbixVersion(t, isZabbixServerVersion54OrHigher) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixItemDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixItemPreprocessingConfig(groupName, templateName, `
+					tag {
+						tag   = "component"
+						value = "application"
+					}
+					tag {
+						tag = "scope"
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_item.my_item1", "tag.#", "2"),
+				),
+			},
+			{
+				Config: testAccZabbixItemPreprocessingConfig(groupName, templateName, ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_item.my_item1", "tag.#", "0"),
+				),
+			},
+			{
+				Config: testAccZabbixItemPreprocessingConfig(groupName, templateName, `
+					applications = ["1"]
+				`),
+				ExpectError: regexp.MustCompile("applications is not supported from Zabbix 5.4"),
+			},
+		},
+	})
+}
+
 func testAccZabbixItemConfig(groupName, templateName, itemName string) string {
 	return fmt.Sprintf(`
 		data "zabbix_server" "test" {}
@@ -84,6 +332,85 @@ func testAccZabbixItemConfig(groupName, templateName, itemName string) string {
 	`, groupName, templateName, templateName, templateName, itemName, itemName)
 }
 
+func testAccZabbixItemPreprocessingConfig(groupName, templateName, preprocessing string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "zabbix" {
+			name = "%s"
+		}
+
+		resource "zabbix_template" "my_zbx_template" {
+			host = "%s"
+			groups = ["${zabbix_host_group.zabbix.name}"]
+	  	}
+
+		resource "zabbix_item" "my_item1" {
+			name = "requests"
+			key = "app.requests"
+			delay = "0"
+			type = 2
+			value_type = 3
+			host_id = "${zabbix_template.my_zbx_template.id}"
+			%s
+	  	}
+	`, groupName, templateName, preprocessing)
+}
+
+func testAccZabbixItemGroupingConfig(groupName, templateName, grouping string) string {
+	return `
+		resource "zabbix_application" "application_test" {
+			name = "application"
+			host_id = "${zabbix_template.my_zbx_template.id}"
+		}
+	` + testAccZabbixItemPreprocessingConfig(groupName, templateName, grouping)
+}
+
+func testAccZabbixItemDependentConfig(groupName, templateName, dependentArgs string) string {
+	return fmt.Sprintf(`
+		%s
+
+		resource "zabbix_item" "dependent" {
+			name = "requests total"
+			key = "app.requests.total"
+			type = 18
+			value_type = 3
+			master_item_id = zabbix_item.my_item1.id
+			host_id = "${zabbix_template.my_zbx_template.id}"
+			%s
+
+			preprocessing {
+				type   = "jsonpath"
+				params = ["$.stats.requests"]
+			}
+		}
+	`, testAccZabbixItemPreprocessingConfig(groupName, templateName, ""), dependentArgs)
+}
+
+func testAccZabbixItemHTTPAgentConfig(groupName, templateName, httpAgent string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "zabbix" {
+			name = "%s"
+		}
+
+		resource "zabbix_template" "my_zbx_template" {
+			host = "%s"
+			groups = ["${zabbix_host_group.zabbix.name}"]
+	  	}
+
+		resource "zabbix_item" "http" {
+			name = "status"
+			key = "app.status"
+			delay = "1m"
+			type = 19
+			value_type = 4
+			host_id = "${zabbix_template.my_zbx_template.id}"
+
+			http_agent {
+				%s
+			}
+	  	}
+	`, groupName, templateName, httpAgent)
+}
+
 func testAccZabbixItemUpdate(groupName, templateName, itemName string) string {
 	return fmt.Sprintf(`
 		data "zabbix_server" "test" {}
@@ -125,7 +452,7 @@ func testAccZabbixItemExists(resource string) resource.TestCheckFunc {
 }
 
 func testAccCheckZabbixItemDestroy(s *terraform.State) error {
-	api := testAccProvider.Meta().(*zabbix.API)
+	api := testAccProvider.Meta().(*providerMeta).api
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "zabbix_item" {
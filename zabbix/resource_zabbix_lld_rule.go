@@ -3,6 +3,7 @@ package zabbix
 import (
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 
 	"github.com/claranet/go-zabbix-api"
@@ -19,6 +20,7 @@ func resourceZabbixLLDRule() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		CustomizeDiff: resourceZabbixLLDRuleCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"delay": &schema.Schema{
 				Type:     schema.TypeString,
@@ -40,8 +42,11 @@ func resourceZabbixLLDRule() *schema.Resource {
 				Required: true,
 			},
 			"type": &schema.Schema{
-				Type:     schema.TypeInt,
-				Required: true,
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Type of the discovery rule, the name or the value of an item type.",
+				ValidateFunc: validateEnum(ItemTypes),
+				StateFunc:    enumStateFunc(ItemTypes),
 			},
 			"filter": &schema.Schema{
 				Type:     schema.TypeSet,
@@ -49,10 +54,21 @@ func resourceZabbixLLDRule() *schema.Resource {
 				Elem:     schemaLLDRuleFilter(),
 				Required: true,
 			},
+			"http_agent": &schema.Schema{
+				Type:        schema.TypeList,
+				Elem:        schemaItemHTTPAgent(),
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Request of HTTP agent discovery rules, only supported on Zabbix 4.0+.",
+			},
 		},
 	}
 }
 
+func resourceZabbixLLDRuleCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	return customizeDiffHTTPAgent(d)
+}
+
 func schemaLLDRuleFilter() *schema.Resource {
 	return &schema.Resource{
 		Schema: map[string]*schema.Schema{
@@ -95,13 +111,16 @@ func schemaLLDRuleFilterCondition() *schema.Resource {
 }
 
 func resourceZabbixLLDRuleCreate(d *schema.ResourceData, meta interface{}) error {
-	rule := createLLDRuleObject(d)
+	rule, err := createLLDRuleObject(d, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
 
-	return createRetry(d, meta, createLLDRule, rule, resourceZabbixLLDRuleRead)
+	return createRetry(d, meta, createLLDRule, *rule, resourceZabbixLLDRuleRead)
 }
 
 func resourceZabbixLLDRuleRead(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 	params := zabbix.Params{
 		"itemids":      d.Id(),
 		"output":       "extend",
@@ -109,7 +128,7 @@ func resourceZabbixLLDRuleRead(d *schema.ResourceData, meta interface{}) error {
 		"inherited":    false,
 	}
 
-	lldRules, err := api.DiscoveryRulesGet(params)
+	lldRules, err := lldRulesGet(api, params)
 	if err != nil {
 		return err
 	}
@@ -123,7 +142,7 @@ func resourceZabbixLLDRuleRead(d *schema.ResourceData, meta interface{}) error {
 	d.Set("interface_id", lldRule.InterfaceID)
 	d.Set("key", lldRule.Key)
 	d.Set("name", lldRule.Name)
-	d.Set("type", lldRule.Type)
+	d.Set("type", strconv.Itoa(int(lldRule.Type)))
 
 	var terraformConditions []interface{}
 	for _, condition := range lldRule.Filter.Conditions {
@@ -141,11 +160,15 @@ func resourceZabbixLLDRuleRead(d *schema.ResourceData, meta interface{}) error {
 	filter["formula"] = lldRule.Filter.Formula
 
 	d.Set("filter", []interface{}{filter})
+	d.Set("http_agent", readItemHTTPAgent(d, lldRule.Type, lldRule.itemHTTPAgent, itemHTTPAuth{
+		AuthType: lldRule.AuthType,
+		Username: lldRule.Username,
+	}))
 	return nil
 }
 
 func resourceZabbixLLDRuleExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	_, err := api.DiscoveryRulesGetByID(d.Id())
 	if err != nil {
@@ -159,29 +182,45 @@ func resourceZabbixLLDRuleExists(d *schema.ResourceData, meta interface{}) (bool
 }
 
 func resourceZabbixLLDRuleUpdate(d *schema.ResourceData, meta interface{}) error {
-	rule := createLLDRuleObject(d)
+	rule, err := createLLDRuleObject(d, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
 
 	rule.ItemID = d.Id()
-	return createRetry(d, meta, updateLLDRule, rule, resourceZabbixLLDRuleRead)
+	return createRetry(d, meta, updateLLDRule, *rule, resourceZabbixLLDRuleRead)
 }
 
 func resourceZabbixLLDRuleDelete(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	err := api.DiscoveryRulesDeletesByIDs([]string{d.Id()})
 	return err
 }
 
-func createLLDRuleObject(d *schema.ResourceData) zabbix.LLDRule {
-	return zabbix.LLDRule{
-		Delay:       d.Get("delay").(string),
-		HostID:      d.Get("host_id").(string),
-		InterfaceID: d.Get("interface_id").(string),
-		Key:         d.Get("key").(string),
-		Name:        d.Get("name").(string),
-		Type:        zabbix.ItemType(d.Get("type").(int)),
-		Filter:      createLLDRuleConditionObject(d),
+func createLLDRuleObject(d *schema.ResourceData, zabbixVersion string) (*lldRuleObject, error) {
+	rule := lldRuleObject{
+		LLDRule: zabbix.LLDRule{
+			Delay:       d.Get("delay").(string),
+			HostID:      d.Get("host_id").(string),
+			InterfaceID: d.Get("interface_id").(string),
+			Key:         d.Get("key").(string),
+			Name:        d.Get("name").(string),
+			Type:        zabbix.ItemType(enumToInt(ItemTypes, d.Get("type").(string))),
+			Filter:      createLLDRuleConditionObject(d),
+		},
+	}
+
+	httpAgent, httpAuth, err := createItemHTTPAgent(d, zabbixVersion)
+	if err != nil {
+		return nil, err
 	}
+	rule.itemHTTPAgent = *httpAgent
+	rule.AuthType = httpAuth.AuthType
+	rule.Username = httpAuth.Username
+	rule.Password = httpAuth.Password
+
+	return &rule, nil
 }
 
 func createLLDRuleConditionObject(d *schema.ResourceData) zabbix.LLDRuleFilter {
@@ -205,23 +244,40 @@ func createLLDRuleConditionObject(d *schema.ResourceData) zabbix.LLDRuleFilter {
 }
 
 func createLLDRule(rule interface{}, api *zabbix.API) (id string, err error) {
-	rules := zabbix.LLDRules{rule.(zabbix.LLDRule)}
-
-	err = api.DiscoveryRulesCreate(rules)
+	ids, err := callCreate(api, "discoveryrule.create", []lldRuleObject{rule.(lldRuleObject)}, "itemids")
 	if err != nil {
 		return
 	}
-	id = rules[0].ItemID
+	id = ids[0]
 	return
 }
 
 func updateLLDRule(rule interface{}, api *zabbix.API) (id string, err error) {
-	rules := zabbix.LLDRules{rule.(zabbix.LLDRule)}
-
-	err = api.DiscoveryRulesUpdate(rules)
+	ids, err := callCreate(api, "discoveryrule.update", []lldRuleObject{rule.(lldRuleObject)}, "itemids")
 	if err != nil {
 		return
 	}
-	id = rules[0].ItemID
+	id = ids[0]
+	return
+}
+
+// lldRuleObject extends the go-zabbix-api discovery rule with the fields it does
+// not support
+// https://www.zabbix.com/documentation/current/manual/api/reference/discoveryrule/object
+type lldRuleObject struct {
+	zabbix.LLDRule
+	itemHTTPAgent
+	// AuthType, Username and Password replace the go-zabbix-api fields, which
+	// are not sent once cleared
+	AuthType *string `json:"authtype,omitempty"`
+	Username *string `json:"username,omitempty"`
+	Password *string `json:"password,omitempty"`
+}
+
+func lldRulesGet(api *zabbix.API, params zabbix.Params) (res []lldRuleObject, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("discoveryrule.get", params, &res)
 	return
 }
@@ -2,9 +2,11 @@ package zabbix
 
 import (
 	"log"
+	"strings"
 
 	"github.com/claranet/go-zabbix-api"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 )
 
 func resourceZabbixLLDRuleLink() *schema.Resource {
@@ -17,10 +19,19 @@ func resourceZabbixLLDRuleLink() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		CustomizeDiff: resourceZabbixLLDRuleLinkCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"lld_rule_id": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
+				ForceNew: true,
+			},
+			"destroy_behavior": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "leave",
+				Description:  "Leave the prototypes of the rule not managed by Terraform on destroy, or delete them.",
+				ValidateFunc: validation.StringInSlice([]string{"leave", "delete_unmanaged"}, false),
 			},
 			"item_prototype": &schema.Schema{
 				Type:     schema.TypeSet,
@@ -32,6 +43,40 @@ func resourceZabbixLLDRuleLink() *schema.Resource {
 				Elem:     schemaTemplateTriggerPrototype(),
 				Optional: true,
 			},
+			"graph_prototype": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     schemaTemplateGraphPrototype(),
+				Optional: true,
+			},
+			"host_prototype": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     schemaTemplateHostPrototype(),
+				Optional: true,
+			},
+			"server_item_prototype": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        schemaTemplateServerObject("item_id"),
+				Computed:    true,
+				Description: "Item prototypes of the rule not tracked by the link, deleted on apply.",
+			},
+			"server_trigger_prototype": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        schemaTemplateServerObject("trigger_id"),
+				Computed:    true,
+				Description: "Trigger prototypes of the rule not tracked by the link, deleted on apply.",
+			},
+			"server_graph_prototype": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        schemaTemplateServerObject("graph_id"),
+				Computed:    true,
+				Description: "Graph prototypes of the rule not tracked by the link, deleted on apply.",
+			},
+			"server_host_prototype": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        schemaTemplateServerObject("host_id"),
+				Computed:    true,
+				Description: "Host prototypes of the rule not tracked by the link, deleted on apply.",
+			},
 		},
 	}
 }
@@ -66,42 +111,108 @@ func schemaTemplateTriggerPrototype() *schema.Resource {
 	}
 }
 
+func schemaTemplateGraphPrototype() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"local": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"graph_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+		},
+	}
+}
+
+func schemaTemplateHostPrototype() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"local": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"host_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+		},
+	}
+}
+
+// lldRuleLinkObjectTypes are ordered so that trigger and graph prototypes are
+// deleted before the item prototypes they depend on
+var lldRuleLinkObjectTypes = []templateLinkObjectType{
+	{
+		attribute:       "trigger_prototype",
+		serverAttribute: "server_trigger_prototype",
+		idKey:           "trigger_id",
+		deleteMethod:    "triggerprototype.delete",
+		deleteIDKey:     "triggerids",
+		get:             getLLDRuleLinkTriggerPrototypes,
+	},
+	{
+		attribute:       "graph_prototype",
+		serverAttribute: "server_graph_prototype",
+		idKey:           "graph_id",
+		deleteMethod:    "graphprototype.delete",
+		deleteIDKey:     "graphids",
+		get:             getLLDRuleLinkGraphPrototypes,
+	},
+	{
+		attribute:       "host_prototype",
+		serverAttribute: "server_host_prototype",
+		idKey:           "host_id",
+		deleteMethod:    "hostprototype.delete",
+		deleteIDKey:     "hostids",
+		get:             getLLDRuleLinkHostPrototypes,
+	},
+	{
+		attribute:       "item_prototype",
+		serverAttribute: "server_item_prototype",
+		idKey:           "item_id",
+		deleteMethod:    "itemprototype.delete",
+		deleteIDKey:     "itemids",
+		get:             getLLDRuleLinkItemPrototypes,
+	},
+}
+
 func resourceZabbixLLDRuleLinkCreate(d *schema.ResourceData, meta interface{}) error {
+	d.SetId(d.Get("lld_rule_id").(string))
 	return resourceZabbixLLDRuleLinkRead(d, meta)
 }
 
 func resourceZabbixLLDRuleLinkRead(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
-	itemsTerraform, err := getTerraformTemplateItemPrototypes(d, api)
-	if err != nil {
-		return err
-	}
-	d.Set("item_prototype", itemsTerraform)
+	// the id is the only attribute known when importing
+	d.Set("lld_rule_id", d.Id())
+	return readLinkObjects(d, api, lldRuleLinkObjectTypes)
+}
 
-	triggersTerraform, err := getTerraformTemplateTriggerPrototypes(d, api)
+func resourceZabbixLLDRuleLinkExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	api := meta.(*providerMeta).api
+
+	_, err := api.DiscoveryRulesGetByID(d.Id())
 	if err != nil {
-		return err
+		if strings.Contains(err.Error(), "Expected exactly one result") {
+			log.Printf("[DEBUG] LLD rule with id %s doesn't exist", d.Id())
+			return false, nil
+		}
+		return false, err
 	}
-	d.Set("trigger_prototype", triggersTerraform)
-
-	d.SetId(d.Get("lld_rule_id").(string))
-	return nil
+	return true, nil
 }
 
-func resourceZabbixLLDRuleLinkExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	return true, nil
+func resourceZabbixLLDRuleLinkCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	return customizeDiffLinkObjects(d, lldRuleLinkObjectTypes)
 }
 
 func resourceZabbixLLDRuleLinkUpdate(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
-
-	err := updateZabbixTemplateItemPrototypes(d, api)
-	if err != nil {
-		return err
-	}
+	api := meta.(*providerMeta).api
 
-	err = updateZabbixTemplateTriggerPrototypes(d, api)
+	err := updateLinkObjects(d, api, lldRuleLinkObjectTypes)
 	if err != nil {
 		return err
 	}
@@ -109,168 +220,80 @@ func resourceZabbixLLDRuleLinkUpdate(d *schema.ResourceData, meta interface{}) e
 }
 
 func resourceZabbixLLDRuleLinkDelete(d *schema.ResourceData, meta interface{}) error {
-	return nil
+	api := meta.(*providerMeta).api
+
+	return deleteUnmanagedLinkObjects(d, api, lldRuleLinkObjectTypes)
 }
 
-func getTerraformTemplateItemPrototypes(d *schema.ResourceData, api *zabbix.API) ([]interface{}, error) {
-	params := zabbix.Params{
-		"output": "extend",
-		"discoveryids": []string{
-			d.Get("lld_rule_id").(string),
-		},
-		"inherited": false,
-	}
-	items, err := api.ItemPrototypesGet(params)
+func getLLDRuleLinkItemPrototypes(api *zabbix.API, lldRuleID string) ([]templateLinkObject, error) {
+	items, err := api.ItemPrototypesGet(zabbix.Params{
+		"output":       "extend",
+		"discoveryids": []string{lldRuleID},
+		"inherited":    false,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	itemsTerraform := make([]interface{}, len(items))
+	objects := make([]templateLinkObject, len(items))
 	for i, item := range items {
-		var itemTerraform = make(map[string]interface{})
-
-		itemTerraform["local"] = true
-		itemTerraform["item_id"] = item.ItemID
-		itemsTerraform[i] = itemTerraform
+		objects[i] = templateLinkObject{ID: item.ItemID, Name: item.Name}
 	}
-	return itemsTerraform, nil
+	return objects, nil
 }
 
-func getTerraformTemplateTriggerPrototypes(d *schema.ResourceData, api *zabbix.API) ([]interface{}, error) {
-	params := zabbix.Params{
-		"output": "extend",
-		"discoveryids": []string{
-			d.Get("lld_rule_id").(string),
-		},
-		"inherited": false,
-	}
-	triggers, err := api.TriggerPrototypesGet(params)
+func getLLDRuleLinkTriggerPrototypes(api *zabbix.API, lldRuleID string) ([]templateLinkObject, error) {
+	triggers, err := api.TriggerPrototypesGet(zabbix.Params{
+		"output":       "extend",
+		"discoveryids": []string{lldRuleID},
+		"inherited":    false,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	triggersTerraform := make([]interface{}, len(triggers))
+	objects := make([]templateLinkObject, len(triggers))
 	for i, trigger := range triggers {
-		var triggerTerraform = make(map[string]interface{})
-
-		triggerTerraform["local"] = true
-		triggerTerraform["trigger_id"] = trigger.TriggerID
-		triggersTerraform[i] = triggerTerraform
+		objects[i] = templateLinkObject{ID: trigger.TriggerID, Name: trigger.Description}
 	}
-	return triggersTerraform, nil
+	return objects, nil
 }
 
-func updateZabbixTemplateItemPrototypes(d *schema.ResourceData, api *zabbix.API) error {
-	if d.HasChange("item_prototype") {
-		oldV, newV := d.GetChange("item_prototype")
-		oldItems := oldV.(*schema.Set).List()
-		newItems := newV.(*schema.Set).List()
-		var deletedItems []string
-		templatedItems, err := api.ItemPrototypesGet(zabbix.Params{
-			"discoveryids": []string{
-				d.Get("lld_rule_id").(string),
-			},
-			"inherited": true,
-		})
-
-		if err != nil {
-			return err
-		}
-		log.Printf("[DEBUG] Found templated item prototype %#v", templatedItems)
-		for _, oldItem := range oldItems {
-			oldItemValue := oldItem.(map[string]interface{})
-			exist := false
-
-			if oldItemValue["local"] == true {
-				continue
-			}
-
-			for _, newItem := range newItems {
-				newItemValue := newItem.(map[string]interface{})
-				if newItemValue["item_id"].(string) == oldItemValue["item_id"].(string) {
-					exist = true
-				}
-			}
-
-			if !exist {
-				templated := false
+func getLLDRuleLinkGraphPrototypes(api *zabbix.API, lldRuleID string) ([]templateLinkObject, error) {
+	graphs, err := graphsGet(api, "graphprototype.get", zabbix.Params{
+		"discoveryids": []string{lldRuleID},
+		"inherited":    false,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-				for _, templatedItem := range templatedItems {
-					if templatedItem.ItemID == oldItemValue["item_id"].(string) {
-						templated = true
-						break
-					}
-				}
-				if !templated {
-					deletedItems = append(deletedItems, oldItemValue["item_id"].(string))
-				}
-			}
-		}
-		if len(deletedItems) > 0 {
-			log.Printf("[DEBUG] template link will delete item prototype with ids : %#v", deletedItems)
-			_, err := api.ItemPrototypesDeleteIDs(deletedItems)
-			if err != nil {
-				return err
-			}
-		}
+	objects := make([]templateLinkObject, len(graphs))
+	for i, graph := range graphs {
+		objects[i] = templateLinkObject{ID: graph.GraphID, Name: graph.Name}
 	}
-	return nil
+	return objects, nil
 }
 
-func updateZabbixTemplateTriggerPrototypes(d *schema.ResourceData, api *zabbix.API) error {
-	if d.HasChange("trigger_prototype") {
-		oldV, newV := d.GetChange("trigger_prototype")
-		oldTriggers := oldV.(*schema.Set).List()
-		newTriggers := newV.(*schema.Set).List()
-		var deletedTriggers []string
-		templatedTriggers, err := api.TriggerPrototypesGet(zabbix.Params{
-			"output": "extend",
-			"discoveryids": []string{
-				d.Get("lld_rule_id").(string),
-			},
-			"inherited": true,
-		})
-
-		if err != nil {
-			return err
-		}
-		log.Printf("[DEBUG] found templated trigger prototype %#v", templatedTriggers)
-		for _, oldTrigger := range oldTriggers {
-			oldTriggerValue := oldTrigger.(map[string]interface{})
-			exist := false
-
-			if oldTriggerValue["local"] == true {
-				continue
-			}
-
-			for _, newTrigger := range newTriggers {
-				newTriggerValue := newTrigger.(map[string]interface{})
-				if oldTriggerValue["trigger_id"].(string) == newTriggerValue["trigger_id"].(string) {
-					exist = true
-				}
-			}
-
-			if !exist {
-				templated := false
+// getLLDRuleLinkHostPrototypes returns the host prototypes of the rule,
+// go-zabbix-api does not wrap them.
+func getLLDRuleLinkHostPrototypes(api *zabbix.API, lldRuleID string) ([]templateLinkObject, error) {
+	var hosts []struct {
+		HostID string `json:"hostid"`
+		Host   string `json:"host"`
+	}
+	err := api.CallWithErrorParse("hostprototype.get", zabbix.Params{
+		"output":       []string{"hostid", "host"},
+		"discoveryids": []string{lldRuleID},
+		"inherited":    false,
+	}, &hosts)
+	if err != nil {
+		return nil, err
+	}
 
-				for _, templatedTrigger := range templatedTriggers {
-					if templatedTrigger.TriggerID == oldTriggerValue["trigger_id"].(string) {
-						templated = true
-						break
-					}
-				}
-				if !templated {
-					deletedTriggers = append(deletedTriggers, oldTriggerValue["trigger_id"].(string))
-				}
-			}
-		}
-		if len(deletedTriggers) > 0 {
-			log.Printf("[DEBUG] template link will delete trigger prototype with ids : %#v", deletedTriggers)
-			_, err := api.TriggerPrototypesDeleteIDs(deletedTriggers)
-			if err != nil {
-				return err
-			}
-		}
+	objects := make([]templateLinkObject, len(hosts))
+	for i, host := range hosts {
+		objects[i] = templateLinkObject{ID: host.HostID, Name: host.Host}
 	}
-	return nil
+	return objects, nil
 }
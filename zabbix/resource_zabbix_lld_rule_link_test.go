@@ -0,0 +1,359 @@
+package zabbix
+
+import (
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/claranet/go-zabbix-api"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccZabbixLLDRuleLink_Basic(t *testing.T) {
+	strID := acctest.RandString(5)
+	groupName := fmt.Sprintf("host_group_%s", strID)
+	templateName := fmt.Sprintf("template_%s", strID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixLLDRuleLinkDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixLLDRuleLinkConfig(groupName, templateName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "item_prototype.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "trigger_prototype.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "graph_prototype.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "host_prototype.#", "0"),
+				),
+			},
+			{
+				Config: testAccZabbixLLDRuleLinkDeleteTriggerPrototype(groupName, templateName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "item_prototype.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "trigger_prototype.#", "0"),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "server_trigger_prototype.#", "0"),
+				),
+			},
+			{
+				Config: testAccZabbixLLDRuleLinkDeleteItemPrototype(groupName, templateName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "item_prototype.#", "0"),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "trigger_prototype.#", "0"),
+				),
+			},
+			{
+				Config: testAccZabbixLLDRuleLinkConfig(groupName, templateName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "item_prototype.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "trigger_prototype.#", "1"),
+				),
+			},
+			{
+				// the imported prototypes are not tracked yet
+				ResourceName: "zabbix_lld_rule_link.lld_rule_link_test",
+				ImportState:  true,
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("Expected one imported state, got %d", len(states))
+					}
+					for _, attribute := range []string{"server_item_prototype.#", "server_trigger_prototype.#", "server_graph_prototype.#"} {
+						if states[0].Attributes[attribute] != "1" {
+							return fmt.Errorf("Expected %s to be 1, got %s", attribute, states[0].Attributes[attribute])
+						}
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccZabbixLLDRuleLink_DeleteServerPrototypes(t *testing.T) {
+	strID := acctest.RandString(5)
+	groupName := fmt.Sprintf("host_group_%s", strID)
+	templateName := fmt.Sprintf("template_%s", strID)
+
+	var groupID, lldRuleID, itemPrototypeID string
+	var graphPrototypeID, hostPrototypeID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixLLDRuleLinkDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixLLDRuleLinkConfig(groupName, templateName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckResourceID("zabbix_host_group.zabbix", &groupID),
+					testAccCheckResourceID("zabbix_lld_rule.lld_rule_test", &lldRuleID),
+					testAccCheckResourceID("zabbix_item_prototype.item_prototype_test", &itemPrototypeID),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "graph_prototype.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "host_prototype.#", "0"),
+				),
+			},
+			{
+				PreConfig: testAccZabbixLLDRuleLinkCreateServerPrototypes(&groupID, &lldRuleID, &itemPrototypeID, &graphPrototypeID, &hostPrototypeID),
+				Config:    testAccZabbixLLDRuleLinkConfig(groupName, templateName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckServerObjectDelete("graphprototype.get", "graphids", &graphPrototypeID),
+					testAccCheckServerObjectDelete("hostprototype.get", "hostids", &hostPrototypeID),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "item_prototype.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "trigger_prototype.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "graph_prototype.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "host_prototype.#", "0"),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "server_graph_prototype.#", "0"),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "server_host_prototype.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccZabbixLLDRuleLink_DestroyUnmanaged(t *testing.T) {
+	strID := acctest.RandString(5)
+	groupName := fmt.Sprintf("host_group_%s", strID)
+	templateName := fmt.Sprintf("template_%s", strID)
+
+	var groupID, lldRuleID, itemPrototypeID string
+	var graphPrototypeID, hostPrototypeID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixLLDRuleLinkDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixLLDRuleLinkDestroyBehaviorConfig(groupName, templateName, true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckResourceID("zabbix_host_group.zabbix", &groupID),
+					testAccCheckResourceID("zabbix_lld_rule.lld_rule_test", &lldRuleID),
+					testAccCheckResourceID("zabbix_item_prototype.item_prototype_test", &itemPrototypeID),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "destroy_behavior", "delete_unmanaged"),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "item_prototype.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_lld_rule_link.lld_rule_link_test", "server_item_prototype.#", "0"),
+				),
+			},
+			{
+				// the server prototypes are listed by the refresh and their deletion planned
+				PreConfig:          testAccZabbixLLDRuleLinkCreateServerPrototypes(&groupID, &lldRuleID, &itemPrototypeID, &graphPrototypeID, &hostPrototypeID),
+				Config:             testAccZabbixLLDRuleLinkDestroyBehaviorConfig(groupName, templateName, true),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccZabbixLLDRuleLinkDestroyBehaviorConfig(groupName, templateName, false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckServerObjectDelete("graphprototype.get", "graphids", &graphPrototypeID),
+					testAccCheckServerObjectDelete("hostprototype.get", "hostids", &hostPrototypeID),
+					resource.TestCheckResourceAttrSet("zabbix_item_prototype.item_prototype_test", "id"),
+				),
+			},
+		},
+	})
+}
+
+func testAccZabbixLLDRuleLinkBaseConfig(groupName, templateName string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "zabbix" {
+			name = "host group test %s"
+		}
+
+		resource "zabbix_template" "template_test" {
+			host = "%s"
+			groups = [zabbix_host_group.zabbix.name]
+			name = "display name for template test %s"
+		}
+
+		resource "zabbix_lld_rule" "lld_rule_test" {
+			delay = 60
+			host_id = zabbix_template.template_test.id
+			interface_id = "0"
+			key = "key.lolo"
+			name = "test_low_level_discovery_rule"
+			type = "zabbix_agent"
+			filter {
+				condition {
+					macro = "{#TESTMACRO}"
+					value = "^lo$"
+				}
+				eval_type = 0
+			}
+		}
+	`, groupName, templateName, templateName)
+}
+
+func testAccZabbixLLDRuleLinkConfig(groupName, templateName string) string {
+	return testAccZabbixLLDRuleLinkBaseConfig(groupName, templateName) + `
+		resource "zabbix_item_prototype" "item_prototype_test" {
+			delay = 60
+			host_id = zabbix_template.template_test.id
+			rule_id = zabbix_lld_rule.lld_rule_test.id
+			key = "test.key[{#TESTMACRO}]"
+			name = "item_prototype_test"
+		}
+
+		resource "zabbix_trigger_prototype" "trigger_prototype_test" {
+			description = "trigger_prototype_test"
+			expression = "{${zabbix_template.template_test.host}:${zabbix_item_prototype.item_prototype_test.key}.last()}=0"
+			priority = "high"
+		}
+
+		resource "zabbix_graph_prototype" "graph_prototype_test" {
+			name = "graph_prototype_test {#TESTMACRO}"
+			graph_item {
+				item_id = zabbix_item_prototype.item_prototype_test.id
+				color   = "00AA00"
+			}
+		}
+
+		resource "zabbix_lld_rule_link" "lld_rule_link_test" {
+			lld_rule_id = zabbix_lld_rule.lld_rule_test.id
+			item_prototype {
+				item_id = zabbix_item_prototype.item_prototype_test.id
+			}
+			trigger_prototype {
+				trigger_id = zabbix_trigger_prototype.trigger_prototype_test.id
+			}
+			graph_prototype {
+				graph_id = zabbix_graph_prototype.graph_prototype_test.id
+			}
+		}
+	`
+}
+
+func testAccZabbixLLDRuleLinkDeleteTriggerPrototype(groupName, templateName string) string {
+	return testAccZabbixLLDRuleLinkBaseConfig(groupName, templateName) + `
+		resource "zabbix_item_prototype" "item_prototype_test" {
+			delay = 60
+			host_id = zabbix_template.template_test.id
+			rule_id = zabbix_lld_rule.lld_rule_test.id
+			key = "test.key[{#TESTMACRO}]"
+			name = "item_prototype_test"
+		}
+
+		resource "zabbix_lld_rule_link" "lld_rule_link_test" {
+			lld_rule_id = zabbix_lld_rule.lld_rule_test.id
+			item_prototype {
+				item_id = zabbix_item_prototype.item_prototype_test.id
+			}
+		}
+	`
+}
+
+func testAccZabbixLLDRuleLinkDeleteItemPrototype(groupName, templateName string) string {
+	return testAccZabbixLLDRuleLinkBaseConfig(groupName, templateName) + `
+		resource "zabbix_lld_rule_link" "lld_rule_link_test" {
+			lld_rule_id = zabbix_lld_rule.lld_rule_test.id
+		}
+	`
+}
+
+func testAccZabbixLLDRuleLinkDestroyBehaviorConfig(groupName, templateName string, link bool) string {
+	config := testAccZabbixLLDRuleLinkBaseConfig(groupName, templateName) + `
+		resource "zabbix_item_prototype" "item_prototype_test" {
+			delay = 60
+			host_id = zabbix_template.template_test.id
+			rule_id = zabbix_lld_rule.lld_rule_test.id
+			key = "test.key[{#TESTMACRO}]"
+			name = "item_prototype_test"
+		}
+	`
+	if link {
+		config += `
+		resource "zabbix_lld_rule_link" "lld_rule_link_test" {
+			lld_rule_id = zabbix_lld_rule.lld_rule_test.id
+			destroy_behavior = "delete_unmanaged"
+			item_prototype {
+				item_id = zabbix_item_prototype.item_prototype_test.id
+			}
+		}
+	`
+	}
+	return config
+}
+
+func testAccZabbixLLDRuleLinkCreateServerPrototypes(groupID, lldRuleID, itemPrototypeID, graphPrototypeID, hostPrototypeID *string) func() {
+	return func() {
+		api := testAccProvider.Meta().(*providerMeta).api
+
+		ids, err := callCreate(api, "graphprototype.create", []map[string]interface{}{{
+			"name":   "server_graph_prototype {#TESTMACRO}",
+			"width":  900,
+			"height": 200,
+			"gitems": []map[string]interface{}{{
+				"itemid": *itemPrototypeID,
+				"color":  "00AA00",
+			}},
+		}}, "graphids")
+		if err != nil {
+			log.Print(err)
+			return
+		}
+		*graphPrototypeID = ids[0]
+
+		ids, err = callCreate(api, "hostprototype.create", []map[string]interface{}{{
+			"host":   "{#TESTMACRO}",
+			"ruleid": *lldRuleID,
+			"groupLinks": []map[string]interface{}{{
+				"groupid": *groupID,
+			}},
+		}}, "hostids")
+		if err != nil {
+			log.Print(err)
+			return
+		}
+		*hostPrototypeID = ids[0]
+	}
+}
+
+func testAccCheckResourceID(n string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+		*id = rs.Primary.ID
+		return nil
+	}
+}
+
+func testAccCheckServerObjectDelete(method, idsKey string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		api := testAccProvider.Meta().(*providerMeta).api
+
+		var prototypes []map[string]interface{}
+		err := api.CallWithErrorParse(method, zabbix.Params{idsKey: *id}, &prototypes)
+		if err != nil {
+			return err
+		}
+		if len(prototypes) != 0 {
+			return fmt.Errorf("Expected %s to be deleted", *id)
+		}
+		return nil
+	}
+}
+
+func testAccCheckZabbixLLDRuleLinkDestroy(s *terraform.State) error {
+	api := testAccProvider.Meta().(*providerMeta).api
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "zabbix_lld_rule_link" {
+			continue
+		}
+
+		_, err := api.DiscoveryRulesGetByID(rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("LLD rule of the link still exist %s", rs.Primary.ID)
+		}
+
+		expectedError := "Expected exactly one result, got 0."
+		if err.Error() != expectedError {
+			return fmt.Errorf("expected error : %s, got : %s", expectedError, err.Error())
+		}
+	}
+	return nil
+}
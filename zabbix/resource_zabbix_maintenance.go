@@ -0,0 +1,607 @@
+package zabbix
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/claranet/go-zabbix-api"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+// MaintenanceTypes zabbix different maintenance type
+var MaintenanceTypes = map[string]int{
+	"data_collection":    0,
+	"no_data_collection": 1,
+}
+
+// MaintenanceTagsEvalTypes zabbix different problem tag evaluation method
+var MaintenanceTagsEvalTypes = map[string]int{
+	"and_or": 0,
+	"or":     2,
+}
+
+// MaintenanceTagOperators zabbix different problem tag operator
+var MaintenanceTagOperators = map[string]int{
+	"equals":   0,
+	"contains": 2,
+}
+
+// MaintenanceTimePeriodTypes zabbix different time period type
+var MaintenanceTimePeriodTypes = map[string]int{
+	"one_time": 0,
+	"daily":    2,
+	"weekly":   3,
+	"monthly":  4,
+}
+
+// MaintenanceDaysOfWeek zabbix different day of week, each one is a bit of the
+// dayofweek of a time period
+var MaintenanceDaysOfWeek = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// MaintenanceMonths zabbix different month, each one is a bit of the month of a
+// time period
+var MaintenanceMonths = []string{"january", "february", "march", "april", "may", "june", "july", "august", "september", "october", "november", "december"}
+
+var maintenanceStartTimeRegexp = regexp.MustCompile(`^([01]?[0-9]|2[0-3]):([0-5][0-9])$`)
+
+func resourceZabbixMaintenance() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceZabbixMaintenanceCreate,
+		Read:   resourceZabbixMaintenanceRead,
+		Exists: resourceZabbixMaintenanceExists,
+		Update: resourceZabbixMaintenanceUpdate,
+		Delete: resourceZabbixMaintenanceDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the maintenance.",
+			},
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"active_since": &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "Time when the maintenance becomes active, in RFC 3339 format.",
+				ValidateFunc:     validation.ValidateRFC3339TimeString,
+				DiffSuppressFunc: suppressSameTime,
+			},
+			"active_till": &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "Time when the maintenance stops being active, in RFC 3339 format.",
+				ValidateFunc:     validation.ValidateRFC3339TimeString,
+				DiffSuppressFunc: suppressSameTime,
+			},
+			"maintenance_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "data_collection",
+				ValidateFunc: validation.StringInSlice([]string{"data_collection", "no_data_collection"}, false),
+			},
+			"host_groups": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "Names of the host groups under maintenance.",
+			},
+			"hosts": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "Technical names of the hosts under maintenance.",
+			},
+			"time_period": &schema.Schema{
+				Type:     schema.TypeList,
+				Elem:     schemaMaintenanceTimePeriod(),
+				Required: true,
+			},
+			"tags_eval_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "and_or",
+				Description:  "Problem tag evaluation method, only supported on Zabbix 4.0+.",
+				ValidateFunc: validation.StringInSlice([]string{"and_or", "or"}, false),
+			},
+			"tag": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        schemaMaintenanceTag(),
+				Optional:    true,
+				Description: "Problem tags to suppress, only supported on Zabbix 4.0+.",
+			},
+		},
+	}
+}
+
+func schemaMaintenanceTimePeriod() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"type": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"one_time", "daily", "weekly", "monthly"}, false),
+			},
+			"period": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3600,
+				Description:  "Duration of the maintenance period in seconds.",
+				ValidateFunc: validation.IntAtLeast(300),
+			},
+			"start_date": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Start of one time periods, in RFC 3339 format.",
+				ValidateFunc:     validation.ValidateRFC3339TimeString,
+				DiffSuppressFunc: suppressSameTime,
+			},
+			"start_time": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "00:00",
+				Description:  "Start of daily, weekly and monthly periods, in HH:MM format.",
+				ValidateFunc: validation.StringMatch(maintenanceStartTimeRegexp, "must be in HH:MM format"),
+			},
+			"every": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Computed:    true,
+				Description: "Interval of daily and weekly periods, or week of the month of monthly periods.",
+			},
+			"days_of_week": &schema.Schema{
+				Type: schema.TypeSet,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(MaintenanceDaysOfWeek, false),
+				},
+				Optional: true,
+			},
+			"day": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Day of the month of monthly periods.",
+				ValidateFunc: validation.IntBetween(0, 31),
+			},
+			"months": &schema.Schema{
+				Type: schema.TypeSet,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(MaintenanceMonths, false),
+				},
+				Optional: true,
+			},
+		},
+	}
+}
+
+func schemaMaintenanceTag() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"tag": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"operator": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "contains",
+				ValidateFunc: validation.StringInSlice([]string{"equals", "contains"}, false),
+			},
+			"value": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+}
+
+// suppressSameTime ignores the differences between two RFC 3339 times of the
+// same instant, Zabbix only stores timestamps.
+func suppressSameTime(k, old, new string, d *schema.ResourceData) bool {
+	oldTime, err := time.Parse(time.RFC3339, old)
+	if err != nil {
+		return false
+	}
+	newTime, err := time.Parse(time.RFC3339, new)
+	if err != nil {
+		return false
+	}
+	return oldTime.Equal(newTime)
+}
+
+func resourceZabbixMaintenanceCreate(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	maintenance, err := createMaintenanceObj(d, api, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
+
+	return createRetry(d, meta, createMaintenance, *maintenance, resourceZabbixMaintenanceRead)
+}
+
+func resourceZabbixMaintenanceRead(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+	zabbixVersion := getZabbixServerVersion(meta)
+
+	params := zabbix.Params{
+		"maintenanceids":    d.Id(),
+		"output":            "extend",
+		"selectGroups":      "extend",
+		"selectHosts":       "extend",
+		"selectTimeperiods": "extend",
+	}
+	if isZabbixServerVersion40OrHigher(zabbixVersion) {
+		params["selectTags"] = "extend"
+	}
+	maintenances, err := maintenancesGet(api, params)
+	if err != nil {
+		return err
+	}
+	if len(maintenances) != 1 {
+		return fmt.Errorf("Expected one maintenance with id %s and got %d maintenances", d.Id(), len(maintenances))
+	}
+	maintenance := maintenances[0]
+
+	d.Set("name", maintenance.Name)
+	d.Set("description", maintenance.Description)
+	d.Set("active_since", timestampToRFC3339(maintenance.ActiveSince))
+	d.Set("active_till", timestampToRFC3339(maintenance.ActiveTill))
+	for name, maintenanceType := range MaintenanceTypes {
+		if strconv.Itoa(maintenanceType) == maintenance.MaintenanceType {
+			d.Set("maintenance_type", name)
+		}
+	}
+
+	groups := []string{}
+	if maintenance.Groups != nil {
+		for _, group := range *maintenance.Groups {
+			groups = append(groups, group.Name)
+		}
+	}
+	d.Set("host_groups", groups)
+
+	hosts := []string{}
+	if maintenance.Hosts != nil {
+		for _, host := range *maintenance.Hosts {
+			hosts = append(hosts, host.Host)
+		}
+	}
+	d.Set("hosts", hosts)
+
+	timePeriods := make([]interface{}, len(maintenance.TimePeriods))
+	for i, timePeriod := range maintenance.TimePeriods {
+		timePeriods[i] = readMaintenanceTimePeriod(timePeriod)
+	}
+	d.Set("time_period", timePeriods)
+
+	if isZabbixServerVersion40OrHigher(zabbixVersion) {
+		for name, evalType := range MaintenanceTagsEvalTypes {
+			if strconv.Itoa(evalType) == maintenance.TagsEvalType {
+				d.Set("tags_eval_type", name)
+			}
+		}
+
+		tags := []interface{}{}
+		if maintenance.Tags == nil {
+			maintenance.Tags = &[]maintenanceTag{}
+		}
+		for _, tag := range *maintenance.Tags {
+			value := map[string]interface{}{
+				"tag":   tag.Tag,
+				"value": tag.Value,
+			}
+			for name, operator := range MaintenanceTagOperators {
+				if strconv.Itoa(operator) == tag.Operator {
+					value["operator"] = name
+				}
+			}
+			tags = append(tags, value)
+		}
+		d.Set("tag", tags)
+	}
+
+	log.Printf("[DEBUG] Maintenance name is %s\n", maintenance.Name)
+	return nil
+}
+
+func readMaintenanceTimePeriod(timePeriod maintenanceTimePeriod) map[string]interface{} {
+	startTime := atoi(timePeriod.StartTime)
+	value := map[string]interface{}{
+		"period":       atoi(timePeriod.Period),
+		"start_time":   fmt.Sprintf("%02d:%02d", startTime/3600, startTime%3600/60),
+		"every":        atoi(timePeriod.Every),
+		"day":          atoi(timePeriod.Day),
+		"days_of_week": bitmaskToNames(atoi(timePeriod.DayOfWeek), MaintenanceDaysOfWeek),
+		"months":       bitmaskToNames(atoi(timePeriod.Month), MaintenanceMonths),
+	}
+	for name, periodType := range MaintenanceTimePeriodTypes {
+		if strconv.Itoa(periodType) == timePeriod.TimePeriodType {
+			value["type"] = name
+		}
+	}
+	// Zabbix sets the start date of every period, it is only meaningful for one
+	// time periods
+	if value["type"] == "one_time" {
+		value["start_date"] = timestampToRFC3339(timePeriod.StartDate)
+	}
+	return value
+}
+
+func resourceZabbixMaintenanceExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	api := meta.(*providerMeta).api
+
+	_, err := maintenanceGetByID(api, d.Id())
+	if err != nil {
+		if strings.Contains(err.Error(), "Expected exactly one result") {
+			log.Printf("[DEBUG] Maintenance with id %s doesn't exist", d.Id())
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func resourceZabbixMaintenanceUpdate(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	maintenance, err := createMaintenanceObj(d, api, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
+
+	maintenance.MaintenanceID = d.Id()
+	return createRetry(d, meta, updateMaintenance, *maintenance, resourceZabbixMaintenanceRead)
+}
+
+func resourceZabbixMaintenanceDelete(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	return callDeleteByIDs(api, "maintenance.delete", []string{d.Id()}, "maintenanceids")
+}
+
+func createMaintenanceObj(d *schema.ResourceData, api *zabbix.API, zabbixVersion string) (*maintenance, error) {
+	activeSince, _ := time.Parse(time.RFC3339, d.Get("active_since").(string))
+	activeTill, _ := time.Parse(time.RFC3339, d.Get("active_till").(string))
+
+	maintenance := maintenance{
+		Name:            d.Get("name").(string),
+		Description:     d.Get("description").(string),
+		ActiveSince:     strconv.FormatInt(activeSince.Unix(), 10),
+		ActiveTill:      strconv.FormatInt(activeTill.Unix(), 10),
+		MaintenanceType: strconv.Itoa(MaintenanceTypes[d.Get("maintenance_type").(string)]),
+	}
+
+	groupIDs := []string{}
+	if groupNames := getStringSet(d, "host_groups"); len(groupNames) > 0 {
+		groups, err := getHostGroupsByName(api, groupNames)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			groupIDs = append(groupIDs, group.GroupID)
+		}
+	}
+
+	hostIDs := []string{}
+	if hostNames := getStringSet(d, "hosts"); len(hostNames) > 0 {
+		hosts, err := getHostsByName(api, hostNames)
+		if err != nil {
+			return nil, err
+		}
+		for _, host := range hosts {
+			hostIDs = append(hostIDs, host.HostID)
+		}
+	}
+
+	if isZabbixServerVersion60OrHigher(zabbixVersion) {
+		groups := make([]maintenanceGroup, len(groupIDs))
+		for i, id := range groupIDs {
+			groups[i] = maintenanceGroup{GroupID: id}
+		}
+		hosts := make([]maintenanceHost, len(hostIDs))
+		for i, id := range hostIDs {
+			hosts[i] = maintenanceHost{HostID: id}
+		}
+		maintenance.Groups = &groups
+		maintenance.Hosts = &hosts
+	} else {
+		maintenance.GroupIDs = &groupIDs
+		maintenance.HostIDs = &hostIDs
+	}
+
+	for i, terraformTimePeriod := range d.Get("time_period").([]interface{}) {
+		timePeriod, err := createMaintenanceTimePeriod(terraformTimePeriod.(map[string]interface{}))
+		if err != nil {
+			return nil, fmt.Errorf("time_period.%d: %s", i, err)
+		}
+		maintenance.TimePeriods = append(maintenance.TimePeriods, *timePeriod)
+	}
+
+	terraformTags := d.Get("tag").(*schema.Set).List()
+	if isZabbixServerVersion40OrHigher(zabbixVersion) {
+		maintenance.TagsEvalType = strconv.Itoa(MaintenanceTagsEvalTypes[d.Get("tags_eval_type").(string)])
+		tags := make([]maintenanceTag, len(terraformTags))
+		for i, terraformTag := range terraformTags {
+			value := terraformTag.(map[string]interface{})
+			tags[i] = maintenanceTag{
+				Tag:      value["tag"].(string),
+				Operator: strconv.Itoa(MaintenanceTagOperators[value["operator"].(string)]),
+				Value:    value["value"].(string),
+			}
+		}
+		maintenance.Tags = &tags
+	} else if len(terraformTags) > 0 || d.Get("tags_eval_type").(string) != "and_or" {
+		return nil, fmt.Errorf("tag and tags_eval_type are only supported from Zabbix 4.0")
+	}
+
+	return &maintenance, nil
+}
+
+func createMaintenanceTimePeriod(value map[string]interface{}) (*maintenanceTimePeriod, error) {
+	periodType := value["type"].(string)
+	timePeriod := maintenanceTimePeriod{
+		TimePeriodType: strconv.Itoa(MaintenanceTimePeriodTypes[periodType]),
+		Period:         strconv.Itoa(value["period"].(int)),
+	}
+
+	if periodType == "one_time" {
+		if value["start_date"].(string) == "" {
+			return nil, fmt.Errorf("start_date is required by one time periods")
+		}
+		startDate, _ := time.Parse(time.RFC3339, value["start_date"].(string))
+		timePeriod.StartDate = strconv.FormatInt(startDate.Unix(), 10)
+		return &timePeriod, nil
+	}
+
+	match := maintenanceStartTimeRegexp.FindStringSubmatch(value["start_time"].(string))
+	timePeriod.StartTime = strconv.Itoa(atoi(match[1])*3600 + atoi(match[2])*60)
+	if every := value["every"].(int); every != 0 {
+		timePeriod.Every = strconv.Itoa(every)
+	}
+
+	daysOfWeek := namesToBitmask(setToStringSlice(value["days_of_week"].(*schema.Set)), MaintenanceDaysOfWeek)
+	months := namesToBitmask(setToStringSlice(value["months"].(*schema.Set)), MaintenanceMonths)
+	switch periodType {
+	case "weekly":
+		if daysOfWeek == 0 {
+			return nil, fmt.Errorf("days_of_week is required by weekly periods")
+		}
+		timePeriod.DayOfWeek = strconv.Itoa(daysOfWeek)
+	case "monthly":
+		if months == 0 {
+			return nil, fmt.Errorf("months is required by monthly periods")
+		}
+		day := value["day"].(int)
+		if (day == 0) == (daysOfWeek == 0) {
+			return nil, fmt.Errorf("exactly one of day or days_of_week is required by monthly periods")
+		}
+		timePeriod.Month = strconv.Itoa(months)
+		if day != 0 {
+			timePeriod.Day = strconv.Itoa(day)
+		} else {
+			timePeriod.DayOfWeek = strconv.Itoa(daysOfWeek)
+		}
+	}
+	return &timePeriod, nil
+}
+
+// namesToBitmask sets the bit of the index in names of each value.
+func namesToBitmask(values []string, names []string) int {
+	bitmask := 0
+	for _, value := range values {
+		for i, name := range names {
+			if name == value {
+				bitmask |= 1 << uint(i)
+			}
+		}
+	}
+	return bitmask
+}
+
+func bitmaskToNames(bitmask int, names []string) []string {
+	values := []string{}
+	for i, name := range names {
+		if bitmask&(1<<uint(i)) != 0 {
+			values = append(values, name)
+		}
+	}
+	return values
+}
+
+func timestampToRFC3339(timestamp string) string {
+	return time.Unix(int64(atoi(timestamp)), 0).UTC().Format(time.RFC3339)
+}
+
+func createMaintenance(obj interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "maintenance.create", []maintenance{obj.(maintenance)}, "maintenanceids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+func updateMaintenance(obj interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "maintenance.update", []maintenance{obj.(maintenance)}, "maintenanceids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+// maintenance represent Zabbix maintenance object
+// https://www.zabbix.com/documentation/current/manual/api/reference/maintenance/object
+type maintenance struct {
+	MaintenanceID   string                  `json:"maintenanceid,omitempty"`
+	Name            string                  `json:"name"`
+	Description     string                  `json:"description"`
+	ActiveSince     string                  `json:"active_since"`
+	ActiveTill      string                  `json:"active_till"`
+	MaintenanceType string                  `json:"maintenance_type"`
+	TagsEvalType    string                  `json:"tags_evaltype,omitempty"`
+	GroupIDs        *[]string               `json:"groupids,omitempty"`
+	HostIDs         *[]string               `json:"hostids,omitempty"`
+	Groups          *[]maintenanceGroup     `json:"groups,omitempty"`
+	Hosts           *[]maintenanceHost      `json:"hosts,omitempty"`
+	TimePeriods     []maintenanceTimePeriod `json:"timeperiods"`
+	Tags            *[]maintenanceTag       `json:"tags,omitempty"`
+}
+
+type maintenanceGroup struct {
+	GroupID string `json:"groupid"`
+	Name    string `json:"name,omitempty"`
+}
+
+type maintenanceHost struct {
+	HostID string `json:"hostid"`
+	Host   string `json:"host,omitempty"`
+}
+
+type maintenanceTimePeriod struct {
+	TimePeriodType string `json:"timeperiod_type"`
+	Period         string `json:"period"`
+	StartDate      string `json:"start_date,omitempty"`
+	StartTime      string `json:"start_time,omitempty"`
+	Every          string `json:"every,omitempty"`
+	DayOfWeek      string `json:"dayofweek,omitempty"`
+	Day            string `json:"day,omitempty"`
+	Month          string `json:"month,omitempty"`
+}
+
+type maintenanceTag struct {
+	Tag      string `json:"tag"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+func maintenancesGet(api *zabbix.API, params zabbix.Params) (res []maintenance, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("maintenance.get", params, &res)
+	return
+}
+
+func maintenanceGetByID(api *zabbix.API, id string) (*maintenance, error) {
+	maintenances, err := maintenancesGet(api, zabbix.Params{"maintenanceids": id})
+	if err != nil {
+		return nil, err
+	}
+	if len(maintenances) != 1 {
+		return nil, expectOneResult(len(maintenances))
+	}
+	return &maintenances[0], nil
+}
@@ -0,0 +1,161 @@
+package zabbix
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccZabbixMaintenance_Basic(t *testing.T) {
+	strID := acctest.RandString(5)
+	maintenanceName := fmt.Sprintf("maintenance_%s", strID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixMaintenanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixMaintenanceConfig(strID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixMaintenanceExists("zabbix_maintenance.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_maintenance.zabbix", "name", maintenanceName),
+					resource.TestCheckResourceAttr("zabbix_maintenance.zabbix", "active_since", "2030-01-01T00:00:00Z"),
+					resource.TestCheckResourceAttr("zabbix_maintenance.zabbix", "maintenance_type", "data_collection"),
+					resource.TestCheckResourceAttr("zabbix_maintenance.zabbix", "host_groups.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_maintenance.zabbix", "time_period.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_maintenance.zabbix", "time_period.0.type", "one_time"),
+				),
+			},
+			{
+				Config: testAccZabbixMaintenanceUpdateConfig(strID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixMaintenanceExists("zabbix_maintenance.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_maintenance.zabbix", "name", fmt.Sprintf("update_%s", maintenanceName)),
+					resource.TestCheckResourceAttr("zabbix_maintenance.zabbix", "maintenance_type", "no_data_collection"),
+					resource.TestCheckResourceAttr("zabbix_maintenance.zabbix", "hosts.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_maintenance.zabbix", "time_period.#", "2"),
+					resource.TestCheckResourceAttr("zabbix_maintenance.zabbix", "time_period.0.type", "weekly"),
+					resource.TestCheckResourceAttr("zabbix_maintenance.zabbix", "time_period.0.start_time", "22:30"),
+					resource.TestCheckResourceAttr("zabbix_maintenance.zabbix", "time_period.0.days_of_week.#", "2"),
+					resource.TestCheckResourceAttr("zabbix_maintenance.zabbix", "time_period.1.type", "monthly"),
+					resource.TestCheckResourceAttr("zabbix_maintenance.zabbix", "time_period.1.day", "15"),
+				),
+			},
+			{
+				ResourceName:      "zabbix_maintenance.zabbix",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckZabbixMaintenanceDestroy(s *terraform.State) error {
+	api := testAccProvider.Meta().(*providerMeta).api
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "zabbix_maintenance" {
+			continue
+		}
+
+		_, err := maintenanceGetByID(api, rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Maintenance still exists")
+		}
+		expectedError := "Expected exactly one result, got 0."
+		if err.Error() != expectedError {
+			return fmt.Errorf("expected error : %s, got : %s", expectedError, err.Error())
+		}
+	}
+	return nil
+}
+
+func testAccCheckZabbixMaintenanceExists(resource string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resource)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No record ID set")
+		}
+
+		api := testAccProvider.Meta().(*providerMeta).api
+		_, err := maintenanceGetByID(api, rs.Primary.ID)
+		return err
+	}
+}
+
+func testAccZabbixMaintenanceConfig(strID string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "zabbix" {
+			name = "host_group_%s"
+		}
+
+		resource "zabbix_host" "zabbix" {
+			host   = "host_%s"
+			name   = "host_%s"
+			groups = [zabbix_host_group.zabbix.name]
+			interfaces {
+				ip   = "127.0.0.1"
+				main = true
+			}
+		}
+
+		resource "zabbix_maintenance" "zabbix" {
+			name         = "maintenance_%s"
+			active_since = "2030-01-01T00:00:00Z"
+			active_till  = "2030-02-01T00:00:00+01:00"
+			host_groups  = [zabbix_host_group.zabbix.name]
+
+			time_period {
+				type       = "one_time"
+				start_date = "2030-01-10T20:00:00Z"
+				period     = 7200
+			}
+		}
+	`, strID, strID, strID, strID)
+}
+
+func testAccZabbixMaintenanceUpdateConfig(strID string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "zabbix" {
+			name = "host_group_%s"
+		}
+
+		resource "zabbix_host" "zabbix" {
+			host   = "host_%s"
+			name   = "host_%s"
+			groups = [zabbix_host_group.zabbix.name]
+			interfaces {
+				ip   = "127.0.0.1"
+				main = true
+			}
+		}
+
+		resource "zabbix_maintenance" "zabbix" {
+			name             = "update_maintenance_%s"
+			active_since     = "2030-01-01T00:00:00Z"
+			active_till      = "2030-02-01T00:00:00+01:00"
+			maintenance_type = "no_data_collection"
+			host_groups      = [zabbix_host_group.zabbix.name]
+			hosts            = [zabbix_host.zabbix.host]
+
+			time_period {
+				type         = "weekly"
+				start_time   = "22:30"
+				days_of_week = ["saturday", "sunday"]
+			}
+
+			time_period {
+				type   = "monthly"
+				day    = 15
+				months = ["january", "july"]
+			}
+		}
+	`, strID, strID, strID, strID)
+}
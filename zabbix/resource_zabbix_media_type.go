@@ -0,0 +1,626 @@
+package zabbix
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/claranet/go-zabbix-api"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+// MediaTypeTypes zabbix different media type
+var MediaTypeTypes = map[string]int{
+	"email":   0,
+	"script":  1,
+	"sms":     2,
+	"webhook": 4,
+}
+
+// MediaTypeMessageModes zabbix different operation mode of message templates
+var MediaTypeMessageModes = map[string]int{
+	"problem":  0,
+	"recovery": 1,
+	"update":   2,
+}
+
+// MediaTypeSMTPSecurities zabbix different SMTP connection security
+var MediaTypeSMTPSecurities = map[string]int{
+	"none":     0,
+	"starttls": 1,
+	"ssl":      2,
+}
+
+var mediaTypeBlocks = []string{"email", "script", "sms", "webhook"}
+
+func resourceZabbixMediaType() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceZabbixMediaTypeCreate,
+		Read:   resourceZabbixMediaTypeRead,
+		Exists: resourceZabbixMediaTypeExists,
+		Update: resourceZabbixMediaTypeUpdate,
+		Delete: resourceZabbixMediaTypeDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the media type.",
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Description of the media type (Zabbix 4.4+).",
+			},
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"max_sessions": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				Description:  "Number of parallel alerts, 0 for unlimited (Zabbix 3.4+).",
+				ValidateFunc: validation.IntBetween(0, 100),
+			},
+			"max_attempts": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				Description:  "Number of attempts to send an alert (Zabbix 3.4+).",
+				ValidateFunc: validation.IntBetween(1, 10),
+			},
+			"attempt_interval": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "10s",
+				Description: "Interval between attempts to send an alert (Zabbix 3.4+).",
+			},
+			"email": &schema.Schema{
+				Type:          schema.TypeList,
+				MaxItems:      1,
+				Elem:          schemaMediaTypeEmail(),
+				Optional:      true,
+				ConflictsWith: []string{"script", "sms", "webhook"},
+			},
+			"script": &schema.Schema{
+				Type:          schema.TypeList,
+				MaxItems:      1,
+				Elem:          schemaMediaTypeScript(),
+				Optional:      true,
+				ConflictsWith: []string{"email", "sms", "webhook"},
+			},
+			"sms": &schema.Schema{
+				Type:          schema.TypeList,
+				MaxItems:      1,
+				Elem:          schemaMediaTypeSMS(),
+				Optional:      true,
+				ConflictsWith: []string{"email", "script", "webhook"},
+			},
+			"webhook": &schema.Schema{
+				Type:          schema.TypeList,
+				MaxItems:      1,
+				Elem:          schemaMediaTypeWebhook(),
+				Optional:      true,
+				ConflictsWith: []string{"email", "script", "sms"},
+			},
+			"message_template": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     schemaMediaTypeMessageTemplate(),
+				Optional: true,
+			},
+		},
+	}
+}
+
+func schemaMediaTypeEmail() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"smtp_server": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"smtp_port": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      25,
+				ValidateFunc: validation.IntBetween(0, 65535),
+			},
+			"smtp_helo": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"smtp_email": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Email address the notifications are sent from.",
+			},
+			"security": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "none",
+				ValidateFunc: validation.StringInSlice([]string{"none", "starttls", "ssl"}, false),
+			},
+			"verify_peer": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"verify_host": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"username": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "Username used to authenticate on the SMTP server, authentication is disabled when empty.",
+			},
+			"password": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Default:   "",
+				Sensitive: true,
+			},
+			"html": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether messages are sent as HTML or plain text (Zabbix 5.0+).",
+			},
+		},
+	}
+}
+
+func schemaMediaTypeScript() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the script in the AlertScriptsPath directory.",
+			},
+			"parameters": &schema.Schema{
+				Type:     schema.TypeList,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+			},
+		},
+	}
+}
+
+func schemaMediaTypeSMS() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"gsm_modem": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Serial device name of the GSM modem.",
+			},
+		},
+	}
+}
+
+func schemaMediaTypeWebhook() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"script": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "JavaScript body of the webhook.",
+			},
+			"parameters": &schema.Schema{
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "Parameters passed to the webhook script.",
+			},
+			"timeout": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "30s",
+			},
+			"process_tags": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the tags returned by the script are added to the event.",
+			},
+			"show_event_menu": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"event_menu_url": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"event_menu_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+		},
+	}
+}
+
+func schemaMediaTypeMessageTemplate() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"event_source": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"trigger", "discovery", "autoregistration", "internal"}, false),
+			},
+			"operation_mode": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "problem",
+				ValidateFunc: validation.StringInSlice([]string{"problem", "recovery", "update"}, false),
+			},
+			"subject": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+			"message": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+			},
+		},
+	}
+}
+
+func resourceZabbixMediaTypeCreate(d *schema.ResourceData, meta interface{}) error {
+	mediaType, err := createMediaTypeObj(d, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
+
+	return createRetry(d, meta, createMediaType, *mediaType, resourceZabbixMediaTypeRead)
+}
+
+func resourceZabbixMediaTypeRead(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+	zabbixVersion := getZabbixServerVersion(meta)
+
+	params := zabbix.Params{
+		"mediatypeids": d.Id(),
+		"output":       "extend",
+	}
+	if isZabbixServerVersion50OrHigher(zabbixVersion) {
+		params["selectMessageTemplates"] = "extend"
+	}
+	mediaTypes, err := mediaTypesGet(api, params)
+	if err != nil {
+		return err
+	}
+	if len(mediaTypes) != 1 {
+		return fmt.Errorf("Expected one media type with id %s and got %d media types", d.Id(), len(mediaTypes))
+	}
+	mediaType := mediaTypes[0]
+
+	if isZabbixServerVersion44OrHigher(zabbixVersion) {
+		d.Set("name", mediaType.Name)
+		d.Set("description", mediaType.Description)
+	} else {
+		// the name of the media type was stored in description before Zabbix 4.4
+		d.Set("name", mediaType.Description)
+	}
+	d.Set("enabled", mediaType.Status == "0")
+	if isZabbixServerVersion34OrHigher(zabbixVersion) {
+		d.Set("max_sessions", atoi(mediaType.MaxSessions))
+		d.Set("max_attempts", atoi(mediaType.MaxAttempts))
+		d.Set("attempt_interval", mediaType.AttemptInterval)
+	}
+
+	for _, block := range mediaTypeBlocks {
+		if MediaTypeTypes[block] != mediaType.Type {
+			d.Set(block, nil)
+		}
+	}
+	switch mediaType.Type {
+	case MediaTypeTypes["email"]:
+		email := map[string]interface{}{
+			"smtp_server": stringValue(mediaType.SMTPServer),
+			"smtp_port":   atoi(stringValue(mediaType.SMTPPort)),
+			"smtp_helo":   stringValue(mediaType.SMTPHelo),
+			"smtp_email":  stringValue(mediaType.SMTPEmail),
+			"security":    "none",
+			"verify_peer": stringValue(mediaType.SMTPVerifyPeer) == "1",
+			"verify_host": stringValue(mediaType.SMTPVerifyHost) == "1",
+			"username":    stringValue(mediaType.Username),
+			// the password is not read back to keep it out of the drift detection
+			"password": d.Get("email.0.password").(string),
+			"html":     stringValue(mediaType.ContentType) != "0",
+		}
+		for name, security := range MediaTypeSMTPSecurities {
+			if strconv.Itoa(security) == stringValue(mediaType.SMTPSecurity) {
+				email["security"] = name
+			}
+		}
+		if !isZabbixServerVersion50OrHigher(zabbixVersion) {
+			email["html"] = d.Get("email.0.html").(bool)
+		}
+		d.Set("email", []interface{}{email})
+	case MediaTypeTypes["script"]:
+		var parameters []string
+		for _, parameter := range strings.Split(stringValue(mediaType.ExecParams), "\n") {
+			if parameter != "" {
+				parameters = append(parameters, parameter)
+			}
+		}
+		d.Set("script", []interface{}{
+			map[string]interface{}{
+				"name":       stringValue(mediaType.ExecPath),
+				"parameters": parameters,
+			},
+		})
+	case MediaTypeTypes["sms"]:
+		d.Set("sms", []interface{}{
+			map[string]interface{}{
+				"gsm_modem": stringValue(mediaType.GSMModem),
+			},
+		})
+	case MediaTypeTypes["webhook"]:
+		parameters := make(map[string]interface{})
+		if mediaType.Parameters != nil {
+			for _, parameter := range *mediaType.Parameters {
+				parameters[parameter.Name] = parameter.Value
+			}
+		}
+		d.Set("webhook", []interface{}{
+			map[string]interface{}{
+				"script":          stringValue(mediaType.Script),
+				"parameters":      parameters,
+				"timeout":         stringValue(mediaType.Timeout),
+				"process_tags":    stringValue(mediaType.ProcessTags) == "1",
+				"show_event_menu": stringValue(mediaType.ShowEventMenu) == "1",
+				"event_menu_url":  stringValue(mediaType.EventMenuURL),
+				"event_menu_name": stringValue(mediaType.EventMenuName),
+			},
+		})
+	}
+
+	var messageTemplates []interface{}
+	if mediaType.MessageTemplates != nil {
+		for _, template := range *mediaType.MessageTemplates {
+			messageTemplate := map[string]interface{}{
+				"subject": template.Subject,
+				"message": template.Message,
+			}
+			for name, eventSource := range ActionEventSources {
+				if strconv.Itoa(eventSource) == template.EventSource {
+					messageTemplate["event_source"] = name
+				}
+			}
+			for name, mode := range MediaTypeMessageModes {
+				if strconv.Itoa(mode) == template.Recovery {
+					messageTemplate["operation_mode"] = name
+				}
+			}
+			messageTemplates = append(messageTemplates, messageTemplate)
+		}
+	}
+	d.Set("message_template", messageTemplates)
+
+	log.Printf("[DEBUG] Media type name is %s\n", d.Get("name").(string))
+	return nil
+}
+
+func resourceZabbixMediaTypeExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	api := meta.(*providerMeta).api
+
+	_, err := mediaTypeGetByID(api, d.Id())
+	if err != nil {
+		if strings.Contains(err.Error(), "Expected exactly one result") {
+			log.Printf("[DEBUG] Media type with id %s doesn't exist", d.Id())
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func resourceZabbixMediaTypeUpdate(d *schema.ResourceData, meta interface{}) error {
+	mediaType, err := createMediaTypeObj(d, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
+
+	mediaType.MediaTypeID = d.Id()
+	return createRetry(d, meta, updateMediaType, *mediaType, resourceZabbixMediaTypeRead)
+}
+
+func resourceZabbixMediaTypeDelete(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	return callDeleteByIDs(api, "mediatype.delete", []string{d.Id()}, "mediatypeids")
+}
+
+func createMediaTypeObj(d *schema.ResourceData, zabbixVersion string) (*mediaType, error) {
+	mediaType := mediaType{
+		Status: boolToString(!d.Get("enabled").(bool)),
+	}
+
+	if isZabbixServerVersion44OrHigher(zabbixVersion) {
+		mediaType.Name = d.Get("name").(string)
+		mediaType.Description = d.Get("description").(string)
+	} else {
+		if d.Get("description").(string) != "" {
+			return nil, fmt.Errorf("description is not supported by Zabbix %s", zabbixVersion)
+		}
+		mediaType.Description = d.Get("name").(string)
+	}
+
+	if isZabbixServerVersion34OrHigher(zabbixVersion) {
+		mediaType.MaxSessions = strconv.Itoa(d.Get("max_sessions").(int))
+		mediaType.MaxAttempts = strconv.Itoa(d.Get("max_attempts").(int))
+		mediaType.AttemptInterval = d.Get("attempt_interval").(string)
+	}
+
+	found := false
+	for _, block := range mediaTypeBlocks {
+		if v, ok := d.GetOk(block); ok && len(v.([]interface{})) == 1 {
+			found = true
+			mediaType.Type = MediaTypeTypes[block]
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("One of %s must be set", strings.Join(mediaTypeBlocks, ", "))
+	}
+
+	switch mediaType.Type {
+	case MediaTypeTypes["email"]:
+		mediaType.SMTPServer = stringPointer(d.Get("email.0.smtp_server").(string))
+		mediaType.SMTPPort = stringPointer(strconv.Itoa(d.Get("email.0.smtp_port").(int)))
+		mediaType.SMTPHelo = stringPointer(d.Get("email.0.smtp_helo").(string))
+		mediaType.SMTPEmail = stringPointer(d.Get("email.0.smtp_email").(string))
+		mediaType.SMTPSecurity = stringPointer(strconv.Itoa(MediaTypeSMTPSecurities[d.Get("email.0.security").(string)]))
+		mediaType.SMTPVerifyPeer = stringPointer(boolToString(d.Get("email.0.verify_peer").(bool)))
+		mediaType.SMTPVerifyHost = stringPointer(boolToString(d.Get("email.0.verify_host").(bool)))
+		mediaType.Username = stringPointer(d.Get("email.0.username").(string))
+		mediaType.Password = stringPointer(d.Get("email.0.password").(string))
+		mediaType.SMTPAuthentication = stringPointer(boolToString(*mediaType.Username != ""))
+		if isZabbixServerVersion50OrHigher(zabbixVersion) {
+			mediaType.ContentType = stringPointer(boolToString(d.Get("email.0.html").(bool)))
+		}
+	case MediaTypeTypes["script"]:
+		mediaType.ExecPath = stringPointer(d.Get("script.0.name").(string))
+		execParams := ""
+		for _, parameter := range d.Get("script.0.parameters").([]interface{}) {
+			execParams += fmt.Sprintf("%s\n", parameter.(string))
+		}
+		mediaType.ExecParams = &execParams
+	case MediaTypeTypes["sms"]:
+		mediaType.GSMModem = stringPointer(d.Get("sms.0.gsm_modem").(string))
+	case MediaTypeTypes["webhook"]:
+		if !isZabbixServerVersion44OrHigher(zabbixVersion) {
+			return nil, fmt.Errorf("webhook media types are not supported by Zabbix %s", zabbixVersion)
+		}
+		parameters := []mediaTypeParameter{}
+		for name, value := range d.Get("webhook.0.parameters").(map[string]interface{}) {
+			parameters = append(parameters, mediaTypeParameter{Name: name, Value: value.(string)})
+		}
+		mediaType.Parameters = &parameters
+		mediaType.Script = stringPointer(d.Get("webhook.0.script").(string))
+		mediaType.Timeout = stringPointer(d.Get("webhook.0.timeout").(string))
+		mediaType.ProcessTags = stringPointer(boolToString(d.Get("webhook.0.process_tags").(bool)))
+		mediaType.ShowEventMenu = stringPointer(boolToString(d.Get("webhook.0.show_event_menu").(bool)))
+		mediaType.EventMenuURL = stringPointer(d.Get("webhook.0.event_menu_url").(string))
+		mediaType.EventMenuName = stringPointer(d.Get("webhook.0.event_menu_name").(string))
+	}
+
+	terraformTemplates := d.Get("message_template").(*schema.Set).List()
+	if isZabbixServerVersion50OrHigher(zabbixVersion) {
+		templates := []mediaTypeMessageTemplate{}
+		for _, terraformTemplate := range terraformTemplates {
+			value := terraformTemplate.(map[string]interface{})
+			templates = append(templates, mediaTypeMessageTemplate{
+				EventSource: strconv.Itoa(ActionEventSources[value["event_source"].(string)]),
+				Recovery:    strconv.Itoa(MediaTypeMessageModes[value["operation_mode"].(string)]),
+				Subject:     value["subject"].(string),
+				Message:     value["message"].(string),
+			})
+		}
+		mediaType.MessageTemplates = &templates
+	} else if len(terraformTemplates) > 0 {
+		return nil, fmt.Errorf("message_template is not supported by Zabbix %s", zabbixVersion)
+	}
+
+	return &mediaType, nil
+}
+
+func createMediaType(obj interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "mediatype.create", []mediaType{obj.(mediaType)}, "mediatypeids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+func updateMediaType(obj interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "mediatype.update", []mediaType{obj.(mediaType)}, "mediatypeids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+// mediaType represent Zabbix media type object, the fields of the selected type
+// are always sent so that cleared values are reset
+// https://www.zabbix.com/documentation/current/manual/api/reference/mediatype/object
+type mediaType struct {
+	MediaTypeID        string                      `json:"mediatypeid,omitempty"`
+	Name               string                      `json:"name,omitempty"`
+	Description        string                      `json:"description"`
+	Type               int                         `json:"type,string"`
+	Status             string                      `json:"status"`
+	MaxSessions        string                      `json:"maxsessions,omitempty"`
+	MaxAttempts        string                      `json:"maxattempts,omitempty"`
+	AttemptInterval    string                      `json:"attempt_interval,omitempty"`
+	SMTPServer         *string                     `json:"smtp_server,omitempty"`
+	SMTPPort           *string                     `json:"smtp_port,omitempty"`
+	SMTPHelo           *string                     `json:"smtp_helo,omitempty"`
+	SMTPEmail          *string                     `json:"smtp_email,omitempty"`
+	SMTPSecurity       *string                     `json:"smtp_security,omitempty"`
+	SMTPVerifyPeer     *string                     `json:"smtp_verify_peer,omitempty"`
+	SMTPVerifyHost     *string                     `json:"smtp_verify_host,omitempty"`
+	SMTPAuthentication *string                     `json:"smtp_authentication,omitempty"`
+	Username           *string                     `json:"username,omitempty"`
+	Password           *string                     `json:"passwd,omitempty"`
+	ContentType        *string                     `json:"content_type,omitempty"`
+	ExecPath           *string                     `json:"exec_path,omitempty"`
+	ExecParams         *string                     `json:"exec_params,omitempty"`
+	GSMModem           *string                     `json:"gsm_modem,omitempty"`
+	Script             *string                     `json:"script,omitempty"`
+	Timeout            *string                     `json:"timeout,omitempty"`
+	ProcessTags        *string                     `json:"process_tags,omitempty"`
+	ShowEventMenu      *string                     `json:"show_event_menu,omitempty"`
+	EventMenuURL       *string                     `json:"event_menu_url,omitempty"`
+	EventMenuName      *string                     `json:"event_menu_name,omitempty"`
+	Parameters         *[]mediaTypeParameter       `json:"parameters,omitempty"`
+	MessageTemplates   *[]mediaTypeMessageTemplate `json:"message_templates,omitempty"`
+}
+
+type mediaTypeParameter struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type mediaTypeMessageTemplate struct {
+	EventSource string `json:"eventsource"`
+	Recovery    string `json:"recovery"`
+	Subject     string `json:"subject"`
+	Message     string `json:"message"`
+}
+
+func mediaTypesGet(api *zabbix.API, params zabbix.Params) (res []mediaType, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("mediatype.get", params, &res)
+	return
+}
+
+func mediaTypeGetByID(api *zabbix.API, id string) (*mediaType, error) {
+	mediaTypes, err := mediaTypesGet(api, zabbix.Params{"mediatypeids": id})
+	if err != nil {
+		return nil, err
+	}
+	if len(mediaTypes) != 1 {
+		return nil, expectOneResult(len(mediaTypes))
+	}
+	return &mediaTypes[0], nil
+}
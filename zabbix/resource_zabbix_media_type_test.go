@@ -0,0 +1,202 @@
+package zabbix
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccZabbixMediaType_Email(t *testing.T) {
+	strID := acctest.RandString(5)
+	mediaTypeName := fmt.Sprintf("media_type_%s", strID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixMediaTypeDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixMediaTypeEmailConfig(mediaTypeName, "smtp.example.com", `
+					smtp_helo = "zabbix.example.com"
+					username  = "zabbix"
+					password  = "secret"
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixMediaTypeExists("zabbix_media_type.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "name", mediaTypeName),
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "email.0.smtp_server", "smtp.example.com"),
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "email.0.security", "starttls"),
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "email.0.password", "secret"),
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "email.0.smtp_helo", "zabbix.example.com"),
+				),
+			},
+			{
+				Config: testAccZabbixMediaTypeEmailConfig(mediaTypeName, "smtp2.example.com", `
+					smtp_helo = "zabbix.example.com"
+					username  = "zabbix"
+					password  = "secret"
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixMediaTypeExists("zabbix_media_type.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "email.0.smtp_server", "smtp2.example.com"),
+				),
+			},
+			{
+				ResourceName:            "zabbix_media_type.zabbix",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"email.0.password"},
+			},
+			{
+				Config: testAccZabbixMediaTypeEmailConfig(mediaTypeName, "smtp2.example.com", ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "email.0.smtp_helo", ""),
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "email.0.username", ""),
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "email.0.password", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccZabbixMediaType_Script(t *testing.T) {
+	strID := acctest.RandString(5)
+	mediaTypeName := fmt.Sprintf("media_type_%s", strID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixMediaTypeDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixMediaTypeScriptConfig(mediaTypeName, `["{ALERT.SENDTO}", "{ALERT.SUBJECT}"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixMediaTypeExists("zabbix_media_type.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "script.0.name", "notify.sh"),
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "script.0.parameters.#", "2"),
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "script.0.parameters.0", "{ALERT.SENDTO}"),
+				),
+			},
+			{
+				Config: testAccZabbixMediaTypeScriptConfig(mediaTypeName, "[]"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "script.0.parameters.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccZabbixMediaType_Webhook(t *testing.T) {
+	strID := acctest.RandString(5)
+	mediaTypeName := fmt.Sprintf("media_type_%s", strID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckZabbixVersion(t, isZabbixServerVersion50OrHigher) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixMediaTypeDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixMediaTypeWebhookConfig(mediaTypeName, `
+					show_event_menu = true
+					event_menu_url  = "https://tickets.example.com/{EVENT.TAGS.ticket}"
+					event_menu_name = "Ticket {EVENT.TAGS.ticket}"
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixMediaTypeExists("zabbix_media_type.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "webhook.0.show_event_menu", "true"),
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "webhook.0.event_menu_name", "Ticket {EVENT.TAGS.ticket}"),
+				),
+			},
+			{
+				Config: testAccZabbixMediaTypeWebhookConfig(mediaTypeName, ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "webhook.0.show_event_menu", "false"),
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "webhook.0.event_menu_url", ""),
+					resource.TestCheckResourceAttr("zabbix_media_type.zabbix", "webhook.0.event_menu_name", ""),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckZabbixMediaTypeDestroy(s *terraform.State) error {
+	api := testAccProvider.Meta().(*providerMeta).api
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "zabbix_media_type" {
+			continue
+		}
+
+		_, err := mediaTypeGetByID(api, rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Media type still exists")
+		}
+		expectedError := "Expected exactly one result, got 0."
+		if err.Error() != expectedError {
+			return fmt.Errorf("expected error : %s, got : %s", expectedError, err.Error())
+		}
+	}
+	return nil
+}
+
+func testAccCheckZabbixMediaTypeExists(resource string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resource)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No record ID set")
+		}
+
+		api := testAccProvider.Meta().(*providerMeta).api
+		_, err := mediaTypeGetByID(api, rs.Primary.ID)
+		return err
+	}
+}
+
+func testAccZabbixMediaTypeEmailConfig(name, server, email string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_media_type" "zabbix" {
+			name = "%s"
+
+			email {
+				smtp_server = "%s"
+				smtp_port   = 587
+				smtp_email  = "zabbix@example.com"
+				security    = "starttls"
+				%s
+			}
+		}
+	`, name, server, email)
+}
+
+func testAccZabbixMediaTypeScriptConfig(name, parameters string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_media_type" "zabbix" {
+			name = "%s"
+
+			script {
+				name       = "notify.sh"
+				parameters = %s
+			}
+		}
+	`, name, parameters)
+}
+
+func testAccZabbixMediaTypeWebhookConfig(name, webhook string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_media_type" "zabbix" {
+			name = "%s"
+
+			webhook {
+				script = "return 'OK';"
+				%s
+			}
+		}
+	`, name, webhook)
+}
@@ -0,0 +1,364 @@
+package zabbix
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/claranet/go-zabbix-api"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+// ProxyModes zabbix different proxy mode
+var ProxyModes = map[string]int{
+	"active":  5,
+	"passive": 6,
+}
+
+// ProxyTLSModes zabbix different encryption of the connections with a proxy,
+// each one is a bit of tls_accept
+var ProxyTLSModes = map[string]int{
+	"unencrypted": 1,
+	"psk":         2,
+	"certificate": 4,
+}
+
+func resourceZabbixProxy() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceZabbixProxyCreate,
+		Read:   resourceZabbixProxyRead,
+		Exists: resourceZabbixProxyExists,
+		Update: resourceZabbixProxyUpdate,
+		Delete: resourceZabbixProxyDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the proxy, as configured by Hostname on the proxy.",
+			},
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"mode": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "active",
+				ValidateFunc: validation.StringInSlice([]string{"active", "passive"}, false),
+			},
+			"interface": &schema.Schema{
+				Type:        schema.TypeList,
+				Elem:        schemaProxyInterface(),
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Interface used to connect to passive proxies.",
+			},
+			"allowed_addresses": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Comma-separated addresses accepted from active proxies, only supported on Zabbix 4.0+.",
+			},
+			"tls_connect": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "unencrypted",
+				Description:  "Encryption of the connections to passive proxies.",
+				ValidateFunc: validation.StringInSlice([]string{"unencrypted", "psk", "certificate"}, false),
+			},
+			"tls_accept": &schema.Schema{
+				Type: schema.TypeSet,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice([]string{"unencrypted", "psk", "certificate"}, false),
+				},
+				Optional:    true,
+				Computed:    true,
+				Description: "Encryptions accepted from active proxies.",
+			},
+			"tls_issuer": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"tls_subject": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"tls_psk_identity": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"tls_psk": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Pre-shared key, never read back from Zabbix.",
+			},
+		},
+	}
+}
+
+func schemaProxyInterface() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"dns": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"ip": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"port": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "10051",
+			},
+		},
+	}
+}
+
+func resourceZabbixProxyCreate(d *schema.ResourceData, meta interface{}) error {
+	proxy, err := createProxyObj(d, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
+
+	return createRetry(d, meta, createProxy, *proxy, resourceZabbixProxyRead)
+}
+
+func resourceZabbixProxyRead(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+	zabbixVersion := getZabbixServerVersion(meta)
+
+	proxies, err := proxiesGet(api, zabbix.Params{
+		"proxyids":        d.Id(),
+		"output":          "extend",
+		"selectInterface": "extend",
+	})
+	if err != nil {
+		return err
+	}
+	if len(proxies) != 1 {
+		return fmt.Errorf("Expected one proxy with id %s and got %d proxies", d.Id(), len(proxies))
+	}
+	proxy := proxies[0]
+
+	d.Set("name", proxy.Host)
+	d.Set("description", proxy.Description)
+	for name, mode := range ProxyModes {
+		if strconv.Itoa(mode) == proxy.Status {
+			d.Set("mode", name)
+		}
+	}
+
+	// The interface of active proxies is returned as an empty array
+	interfaces := []interface{}{}
+	if value, ok := proxy.Interface.(map[string]interface{}); ok && proxy.Status == strconv.Itoa(ProxyModes["passive"]) {
+		// both addresses are read back, useip only follows whether ip is set
+		proxyInterface := map[string]interface{}{
+			"ip":   value["ip"],
+			"dns":  value["dns"],
+			"port": value["port"],
+		}
+		interfaces = append(interfaces, proxyInterface)
+	}
+	d.Set("interface", interfaces)
+
+	if isZabbixServerVersion40OrHigher(zabbixVersion) {
+		if proxy.ProxyAddress != nil {
+			d.Set("allowed_addresses", *proxy.ProxyAddress)
+		}
+	}
+
+	tlsConnect := atoi(proxy.TLSConnect)
+	for name, mode := range ProxyTLSModes {
+		if mode == tlsConnect {
+			d.Set("tls_connect", name)
+		}
+	}
+	tlsAccept := []string{}
+	for name, mode := range ProxyTLSModes {
+		if atoi(proxy.TLSAccept)&mode != 0 {
+			tlsAccept = append(tlsAccept, name)
+		}
+	}
+	d.Set("tls_accept", tlsAccept)
+	d.Set("tls_issuer", proxy.TLSIssuer)
+	d.Set("tls_subject", proxy.TLSSubject)
+	d.Set("tls_psk_identity", proxy.TLSPSKIdentity)
+
+	log.Printf("[DEBUG] Proxy name is %s\n", proxy.Host)
+	return nil
+}
+
+func resourceZabbixProxyExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	api := meta.(*providerMeta).api
+
+	_, err := proxyGetByID(api, d.Id())
+	if err != nil {
+		if strings.Contains(err.Error(), "Expected exactly one result") {
+			log.Printf("[DEBUG] Proxy with id %s doesn't exist", d.Id())
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func resourceZabbixProxyUpdate(d *schema.ResourceData, meta interface{}) error {
+	proxy, err := createProxyObj(d, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
+
+	proxy.ProxyID = d.Id()
+	return createRetry(d, meta, updateProxy, *proxy, resourceZabbixProxyRead)
+}
+
+func resourceZabbixProxyDelete(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	return callDeleteByIDs(api, "proxy.delete", []string{d.Id()}, "proxyids")
+}
+
+func createProxyObj(d *schema.ResourceData, zabbixVersion string) (*proxy, error) {
+	mode := d.Get("mode").(string)
+	proxy := proxy{
+		Host:           d.Get("name").(string),
+		Description:    d.Get("description").(string),
+		Status:         strconv.Itoa(ProxyModes[mode]),
+		TLSConnect:     strconv.Itoa(ProxyTLSModes[d.Get("tls_connect").(string)]),
+		TLSIssuer:      d.Get("tls_issuer").(string),
+		TLSSubject:     d.Get("tls_subject").(string),
+		TLSPSKIdentity: d.Get("tls_psk_identity").(string),
+		TLSPSK:         d.Get("tls_psk").(string),
+	}
+
+	tlsAccept := 0
+	for _, name := range getStringSet(d, "tls_accept") {
+		tlsAccept |= ProxyTLSModes[name]
+	}
+	if tlsAccept == 0 {
+		tlsAccept = ProxyTLSModes["unencrypted"]
+	}
+	proxy.TLSAccept = strconv.Itoa(tlsAccept)
+
+	if isZabbixServerVersion40OrHigher(zabbixVersion) {
+		proxyAddress := d.Get("allowed_addresses").(string)
+		proxy.ProxyAddress = &proxyAddress
+	} else if d.Get("allowed_addresses").(string) != "" {
+		return nil, fmt.Errorf("allowed_addresses is only supported from Zabbix 4.0")
+	}
+
+	interfaces := d.Get("interface").([]interface{})
+	if mode == "passive" {
+		if len(interfaces) == 0 {
+			return nil, fmt.Errorf("interface is required by passive proxies")
+		}
+		value := interfaces[0].(map[string]interface{})
+		proxyInterface := proxyInterface{
+			IP:    value["ip"].(string),
+			DNS:   value["dns"].(string),
+			Port:  value["port"].(string),
+			UseIP: "1",
+		}
+		if proxyInterface.IP == "" && proxyInterface.DNS == "" {
+			return nil, fmt.Errorf("Atleast one of two dns or ip must be set")
+		}
+		if proxyInterface.IP == "" {
+			proxyInterface.UseIP = "0"
+		}
+		proxy.Interface = proxyInterface
+	} else if len(interfaces) > 0 {
+		return nil, fmt.Errorf("interface is only used by passive proxies")
+	}
+
+	return &proxy, nil
+}
+
+// getProxyID returns the id of a proxy given its id or its name.
+func getProxyID(api *zabbix.API, proxy string) (string, error) {
+	if _, err := strconv.Atoi(proxy); err == nil {
+		return proxy, nil
+	}
+
+	proxies, err := proxiesGet(api, zabbix.Params{
+		"output": []string{"proxyid"},
+		"filter": map[string]interface{}{
+			"host": proxy,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(proxies) != 1 {
+		return "", fmt.Errorf("Proxy %s doesnt exist in zabbix server", proxy)
+	}
+	return proxies[0].ProxyID, nil
+}
+
+func createProxy(obj interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "proxy.create", []proxy{obj.(proxy)}, "proxyids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+func updateProxy(obj interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "proxy.update", []proxy{obj.(proxy)}, "proxyids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+// proxy represent Zabbix proxy object
+// https://www.zabbix.com/documentation/current/manual/api/reference/proxy/object
+type proxy struct {
+	ProxyID        string      `json:"proxyid,omitempty"`
+	Host           string      `json:"host"`
+	Status         string      `json:"status"`
+	Description    string      `json:"description"`
+	ProxyAddress   *string     `json:"proxy_address,omitempty"`
+	TLSConnect     string      `json:"tls_connect"`
+	TLSAccept      string      `json:"tls_accept"`
+	TLSIssuer      string      `json:"tls_issuer"`
+	TLSSubject     string      `json:"tls_subject"`
+	TLSPSKIdentity string      `json:"tls_psk_identity"`
+	TLSPSK         string      `json:"tls_psk,omitempty"`
+	Interface      interface{} `json:"interface,omitempty"`
+}
+
+type proxyInterface struct {
+	IP    string `json:"ip"`
+	DNS   string `json:"dns"`
+	Port  string `json:"port"`
+	UseIP string `json:"useip"`
+}
+
+func proxiesGet(api *zabbix.API, params zabbix.Params) (res []proxy, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("proxy.get", params, &res)
+	return
+}
+
+func proxyGetByID(api *zabbix.API, id string) (*proxy, error) {
+	proxies, err := proxiesGet(api, zabbix.Params{"proxyids": id})
+	if err != nil {
+		return nil, err
+	}
+	if len(proxies) != 1 {
+		return nil, expectOneResult(len(proxies))
+	}
+	return &proxies[0], nil
+}
@@ -0,0 +1,115 @@
+package zabbix
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccZabbixProxy_Basic(t *testing.T) {
+	strID := acctest.RandString(5)
+	proxyName := fmt.Sprintf("proxy_%s", strID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixProxyDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixProxyActiveConfig(strID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixProxyExists("zabbix_proxy.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_proxy.zabbix", "name", proxyName),
+					resource.TestCheckResourceAttr("zabbix_proxy.zabbix", "mode", "active"),
+					resource.TestCheckResourceAttr("zabbix_proxy.zabbix", "tls_accept.#", "2"),
+					resource.TestCheckResourceAttr("zabbix_proxy.zabbix", "interface.#", "0"),
+				),
+			},
+			{
+				Config: testAccZabbixProxyPassiveConfig(strID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixProxyExists("zabbix_proxy.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_proxy.zabbix", "mode", "passive"),
+					resource.TestCheckResourceAttr("zabbix_proxy.zabbix", "interface.0.ip", "127.0.0.1"),
+					resource.TestCheckResourceAttr("zabbix_proxy.zabbix", "interface.0.dns", "localhost"),
+					resource.TestCheckResourceAttr("zabbix_proxy.zabbix", "interface.0.port", "10051"),
+					resource.TestCheckResourceAttr("zabbix_proxy.zabbix", "tls_connect", "certificate"),
+				),
+			},
+			{
+				ResourceName:            "zabbix_proxy.zabbix",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"tls_psk"},
+			},
+		},
+	})
+}
+
+func testAccCheckZabbixProxyDestroy(s *terraform.State) error {
+	api := testAccProvider.Meta().(*providerMeta).api
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "zabbix_proxy" {
+			continue
+		}
+
+		_, err := proxyGetByID(api, rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Proxy still exists")
+		}
+		expectedError := "Expected exactly one result, got 0."
+		if err.Error() != expectedError {
+			return fmt.Errorf("expected error : %s, got : %s", expectedError, err.Error())
+		}
+	}
+	return nil
+}
+
+func testAccCheckZabbixProxyExists(resource string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resource)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No record ID set")
+		}
+
+		api := testAccProvider.Meta().(*providerMeta).api
+		_, err := proxyGetByID(api, rs.Primary.ID)
+		return err
+	}
+}
+
+func testAccZabbixProxyActiveConfig(strID string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_proxy" "zabbix" {
+			name             = "proxy_%s"
+			tls_accept       = ["unencrypted", "psk"]
+			tls_psk_identity = "proxy_%s"
+			tls_psk          = "f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff"
+		}
+	`, strID, strID)
+}
+
+func testAccZabbixProxyPassiveConfig(strID string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_proxy" "zabbix" {
+			name        = "proxy_%s"
+			mode        = "passive"
+			tls_connect = "certificate"
+			tls_accept  = ["unencrypted"]
+			tls_issuer  = "CN=Internal CA"
+			tls_subject = "CN=proxy_%s"
+
+			interface {
+				ip  = "127.0.0.1"
+				dns = "localhost"
+			}
+		}
+	`, strID, strID)
+}
@@ -3,12 +3,21 @@ package zabbix
 import (
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 
 	"github.com/claranet/go-zabbix-api"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 )
 
+// MacroTypes zabbix different user macro type
+var MacroTypes = map[string]int{
+	"text":   0,
+	"secret": 1,
+	"vault":  2,
+}
+
 func resourceZabbixTemplate() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceZabbixTemplateCreate,
@@ -29,7 +38,7 @@ func resourceZabbixTemplate() *schema.Resource {
 				Type:        schema.TypeSet,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 				Required:    true,
-				Description: "ID of the Host Group.",
+				Description: "IDs or names of the host groups.",
 			},
 			"name": &schema.Schema{
 				Type:        schema.TypeString,
@@ -42,10 +51,25 @@ func resourceZabbixTemplate() *schema.Resource {
 				Description: "Description of the template.",
 			},
 			"macro": &schema.Schema{
-				Type:        schema.TypeMap,
-				Elem:        &schema.Schema{Type: schema.TypeString},
+				Type:          schema.TypeMap,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Optional:      true,
+				Description:   "User macros for the template.",
+				Deprecated:    "Use user_macro blocks instead, they support descriptions and secret macros. Replace each entry of the map with a user_macro block, the macros are updated in place",
+				ConflictsWith: []string{"user_macro"},
+			},
+			"user_macro": &schema.Schema{
+				Type:          schema.TypeSet,
+				Elem:          schemaUserMacro(),
+				Optional:      true,
+				Description:   "User macros for the template.",
+				ConflictsWith: []string{"macro"},
+			},
+			"tag": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        schemaHostTag(),
 				Optional:    true,
-				Description: "User macros for the template.",
+				Description: "Tags of the template, only supported on Zabbix 4.2+.",
 			},
 			"linked_template": &schema.Schema{
 				Type:     schema.TypeSet,
@@ -56,20 +80,195 @@ func resourceZabbixTemplate() *schema.Resource {
 	}
 }
 
-func createZabbixMacro(d *schema.ResourceData) zabbix.Macros {
-	var macros zabbix.Macros
+func schemaUserMacro() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the macro, with or without {$ }.",
+			},
+			"value": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Value of the macro, the values of secret macros are never read back from Zabbix.",
+			},
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "text",
+				ValidateFunc: validation.StringInSlice([]string{"text", "secret", "vault"}, false),
+			},
+		},
+	}
+}
+
+// normalizeMacroName returns the name of a macro with its {$ } delimiters,
+// names can be given with or without them.
+func normalizeMacroName(name string) string {
+	if strings.HasPrefix(name, "{$") && strings.HasSuffix(name, "}") {
+		return name
+	}
+	return fmt.Sprintf("{$%s}", name)
+}
+
+func createZabbixMacro(d *schema.ResourceData) []userMacro {
+	var macros []userMacro
 
 	terraformMacros := d.Get("macro").(map[string]interface{})
 	for i, terraformMacro := range terraformMacros {
-		macro := zabbix.Macro{
-			MacroName: fmt.Sprintf("{$%s}", i),
-			Value:     terraformMacro.(string),
+		macro := userMacro{
+			Macro: normalizeMacroName(i),
+			Value: terraformMacro.(string),
 		}
 		macros = append(macros, macro)
 	}
 	return macros
 }
 
+func createUserMacro(value map[string]interface{}, zabbixVersion string) (*userMacro, error) {
+	macro := userMacro{
+		Macro: normalizeMacroName(value["name"].(string)),
+		Value: value["value"].(string),
+	}
+
+	description := value["description"].(string)
+	if isZabbixServerVersion44OrHigher(zabbixVersion) {
+		macro.Description = &description
+	} else if description != "" {
+		return nil, fmt.Errorf("The description of the macro %s is only supported from Zabbix 4.4", macro.Macro)
+	}
+
+	macroType := value["type"].(string)
+	if isZabbixServerVersion50OrHigher(zabbixVersion) {
+		macro.Type = strconv.Itoa(MacroTypes[macroType])
+	} else if macroType != "text" {
+		return nil, fmt.Errorf("The %s type of the macro %s is only supported from Zabbix 5.0", macroType, macro.Macro)
+	}
+	if macroType == "vault" && !isZabbixServerVersion52OrHigher(zabbixVersion) {
+		return nil, fmt.Errorf("The vault type of the macro %s is only supported from Zabbix 5.2", macro.Macro)
+	}
+
+	return &macro, nil
+}
+
+// createUserMacros builds the macros of the user_macro blocks of hosts and
+// templates.
+func createUserMacros(d *schema.ResourceData, zabbixVersion string) ([]userMacro, error) {
+	terraformMacros := d.Get("user_macro").(*schema.Set).List()
+	macros := make([]userMacro, len(terraformMacros))
+
+	for i, terraformMacro := range terraformMacros {
+		macro, err := createUserMacro(terraformMacro.(map[string]interface{}), zabbixVersion)
+		if err != nil {
+			return nil, err
+		}
+		macros[i] = *macro
+	}
+	return macros, nil
+}
+
+// readUserMacro returns the user_macro block of a macro. The name keeps the form
+// of the state and, as Zabbix does not return them, the values of secret macros
+// are kept from the state.
+func readUserMacro(macro userMacro, stateMacros []interface{}) map[string]interface{} {
+	terraformMacro := map[string]interface{}{
+		"name":  macro.Macro,
+		"value": macro.Value,
+		"type":  "text",
+	}
+	if macro.Description != nil {
+		terraformMacro["description"] = *macro.Description
+	}
+	for name, macroType := range MacroTypes {
+		if strconv.Itoa(macroType) == macro.Type {
+			terraformMacro["type"] = name
+		}
+	}
+
+	for _, stateMacro := range stateMacros {
+		value := stateMacro.(map[string]interface{})
+		if normalizeMacroName(value["name"].(string)) != macro.Macro {
+			continue
+		}
+		terraformMacro["name"] = value["name"]
+		if terraformMacro["type"] == "secret" {
+			terraformMacro["value"] = value["value"]
+		}
+	}
+	return terraformMacro
+}
+
+func readUserMacros(d *schema.ResourceData, macros []userMacro) []interface{} {
+	stateMacros := d.Get("user_macro").(*schema.Set).List()
+	terraformMacros := make([]interface{}, len(macros))
+
+	for i, macro := range macros {
+		terraformMacros[i] = readUserMacro(macro, stateMacros)
+	}
+	return terraformMacros
+}
+
+func schemaHostTag() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"tag": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"value": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+}
+
+// createHostTags builds the tags of the tag blocks of hosts and templates, they
+// are not sent to servers older than 4.2.
+func createHostTags(d *schema.ResourceData, zabbixVersion string) (*[]hostTag, error) {
+	terraformTags := d.Get("tag").(*schema.Set).List()
+	if !isZabbixServerVersion42OrHigher(zabbixVersion) {
+		if len(terraformTags) > 0 {
+			return nil, fmt.Errorf("tag is only supported from Zabbix 4.2")
+		}
+		return nil, nil
+	}
+
+	tags := createTags(d)
+	return &tags, nil
+}
+
+// createTags builds the tags of the tag blocks of a resource.
+func createTags(d *schema.ResourceData) []hostTag {
+	terraformTags := d.Get("tag").(*schema.Set).List()
+	tags := make([]hostTag, len(terraformTags))
+	for i, terraformTag := range terraformTags {
+		value := terraformTag.(map[string]interface{})
+		tags[i] = hostTag{
+			Tag:   value["tag"].(string),
+			Value: value["value"].(string),
+		}
+	}
+	return tags
+}
+
+func readHostTags(tags []hostTag) []interface{} {
+	terraformTags := make([]interface{}, len(tags))
+	for i, tag := range tags {
+		terraformTags[i] = map[string]interface{}{
+			"tag":   tag.Tag,
+			"value": tag.Value,
+		}
+	}
+	return terraformTags
+}
+
 func createLinkedTemplate(d *schema.ResourceData) zabbix.Templates {
 	var templates zabbix.Templates
 
@@ -83,14 +282,28 @@ func createLinkedTemplate(d *schema.ResourceData) zabbix.Templates {
 	return templates
 }
 
-func createTemplateObj(d *schema.ResourceData, api *zabbix.API) (*zabbix.Template, error) {
-	template := zabbix.Template{
-		Host:            d.Get("host").(string),
-		Name:            d.Get("name").(string),
-		Description:     d.Get("description").(string),
-		UserMacros:      createZabbixMacro(d),
-		LinkedTemplates: createLinkedTemplate(d),
+func createTemplateObj(d *schema.ResourceData, api *zabbix.API, zabbixVersion string) (*templateObject, error) {
+	template := templateObject{
+		Template: zabbix.Template{
+			Host:            d.Get("host").(string),
+			Name:            d.Get("name").(string),
+			Description:     d.Get("description").(string),
+			LinkedTemplates: createLinkedTemplate(d),
+		},
+		Macros: createZabbixMacro(d),
+	}
+	if len(template.Macros) == 0 {
+		macros, err := createUserMacros(d, zabbixVersion)
+		if err != nil {
+			return nil, err
+		}
+		template.Macros = macros
+	}
+	tags, err := createHostTags(d, zabbixVersion)
+	if err != nil {
+		return nil, err
 	}
+	template.Tags = tags
 	hostGroupIDs, err := getHostGroups(d, api)
 	if err != nil {
 		return nil, err
@@ -99,16 +312,16 @@ func createTemplateObj(d *schema.ResourceData, api *zabbix.API) (*zabbix.Templat
 	for i, ID := range hostGroupIDs {
 		template.Groups[i].GroupID = ID.GroupID
 	}
-	if template.UserMacros == nil {
-		template.UserMacros = zabbix.Macros{}
+	if template.Macros == nil {
+		template.Macros = []userMacro{}
 	}
 	return &template, nil
 }
 
 func resourceZabbixTemplateCreate(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
-	template, err := createTemplateObj(d, api)
+	template, err := createTemplateObj(d, api, getZabbixServerVersion(meta))
 	if err != nil {
 		return err
 	}
@@ -117,14 +330,17 @@ func resourceZabbixTemplateCreate(d *schema.ResourceData, meta interface{}) erro
 }
 
 func resourceZabbixTemplateRead(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	params := zabbix.Params{
 		"templateids":  d.Id(),
 		"output":       "extend",
 		"selectMacros": "extend",
 	}
-	templates, err := api.TemplatesGet(params)
+	if isZabbixServerVersion42OrHigher(getZabbixServerVersion(meta)) {
+		params["selectTags"] = "extend"
+	}
+	templates, err := templatesGet(api, params)
 	if err != nil {
 		return err
 	}
@@ -140,11 +356,21 @@ func resourceZabbixTemplateRead(d *schema.ResourceData, meta interface{}) error
 	}
 	d.Set("description", template.Description)
 
-	terraformMacros, err := createTerraformMacro(template)
-	if err != nil {
-		return err
+	// The deprecated macro map is kept until the configuration moves to user_macro blocks
+	if len(d.Get("macro").(map[string]interface{})) > 0 {
+		terraformMacros, err := createTerraformMacro(template.Macros)
+		if err != nil {
+			return err
+		}
+		d.Set("macro", terraformMacros)
+		d.Set("user_macro", []interface{}{})
+	} else {
+		d.Set("macro", map[string]interface{}{})
+		d.Set("user_macro", readUserMacros(d, template.Macros))
+	}
+	if template.Tags != nil {
+		d.Set("tag", readHostTags(*template.Tags))
 	}
-	d.Set("macro", terraformMacros)
 
 	terraformGroups, err := createTerraformTemplateGroup(d, api)
 	if err != nil {
@@ -155,7 +381,7 @@ func resourceZabbixTemplateRead(d *schema.ResourceData, meta interface{}) error
 }
 
 func resourceZabbixTemplateExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	_, err := api.TemplateGetByID(d.Id())
 	if err != nil {
@@ -169,9 +395,9 @@ func resourceZabbixTemplateExists(d *schema.ResourceData, meta interface{}) (boo
 }
 
 func resourceZabbixTemplateUpdate(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
-	template, err := createTemplateObj(d, api)
+	template, err := createTemplateObj(d, api, getZabbixServerVersion(meta))
 	if err != nil {
 		return err
 	}
@@ -182,25 +408,25 @@ func resourceZabbixTemplateUpdate(d *schema.ResourceData, meta interface{}) erro
 }
 
 func resourceZabbixTemplateDelete(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	return api.TemplatesDeleteByIds([]string{d.Id()})
 }
 
-func createTerraformMacro(template zabbix.Template) (map[string]interface{}, error) {
-	terraformMacros := make(map[string]interface{}, len(template.UserMacros))
+func createTerraformMacro(macros []userMacro) (map[string]interface{}, error) {
+	terraformMacros := make(map[string]interface{}, len(macros))
 
-	for _, macro := range template.UserMacros {
+	for _, macro := range macros {
 		var name string
-		if noPrefix := strings.Split(macro.MacroName, "{$"); len(noPrefix) == 2 {
+		if noPrefix := strings.Split(macro.Macro, "{$"); len(noPrefix) == 2 {
 			name = noPrefix[1]
 		} else {
-			return nil, fmt.Errorf("Invalid macro name \"%s\"", macro.MacroName)
+			return nil, fmt.Errorf("Invalid macro name \"%s\"", macro.Macro)
 		}
 		if noSuffix := strings.Split(name, "}"); len(noSuffix) == 2 {
 			name = noSuffix[0]
 		} else {
-			return nil, fmt.Errorf("Invalid macro name \"%s\"", macro.MacroName)
+			return nil, fmt.Errorf("Invalid macro name \"%s\"", macro.Macro)
 		}
 		terraformMacros[name] = macro.Value
 	}
@@ -220,11 +446,7 @@ func createTerraformTemplateGroup(d *schema.ResourceData, api *zabbix.API) ([]st
 		return nil, err
 	}
 
-	groupNames := make([]string, len(groups))
-	for i, g := range groups {
-		groupNames[i] = g.Name
-	}
-	return groupNames, nil
+	return readHostGroups(d, groups), nil
 }
 
 func createTerraformLinkedTemplate(template zabbix.Template) []string {
@@ -257,23 +479,56 @@ func getUnlinkedTemplate(d *schema.ResourceData) zabbix.Templates {
 }
 
 func createTemplate(template interface{}, api *zabbix.API) (id string, err error) {
-	templates := zabbix.Templates{template.(zabbix.Template)}
-
-	err = api.TemplatesCreate(templates)
+	ids, err := callCreate(api, "template.create", []templateObject{template.(templateObject)}, "templateids")
 	if err != nil {
 		return
 	}
-	id = templates[0].TemplateID
+	id = ids[0]
 	return
 }
 
 func updateTemplate(template interface{}, api *zabbix.API) (id string, err error) {
-	templates := zabbix.Templates{template.(zabbix.Template)}
-
-	err = api.TemplatesUpdate(templates)
+	templateID := template.(templateObject).TemplateID
+	_, err = api.CallWithError("template.update", []templateObject{template.(templateObject)})
 	if err != nil {
 		return
 	}
-	id = templates[0].TemplateID
+	id = templateID
+	return
+}
+
+// templateObject extends the go-zabbix-api template with the user macros and
+// the tags of newer servers
+// https://www.zabbix.com/documentation/current/manual/api/reference/template/object
+type templateObject struct {
+	zabbix.Template
+	Macros []userMacro `json:"macros"`
+	Tags   *[]hostTag  `json:"tags,omitempty"`
+}
+
+// hostTag represent Zabbix tag object of hosts, templates and triggers
+// https://www.zabbix.com/documentation/current/manual/api/reference/host/object#host-tag
+type hostTag struct {
+	Tag   string `json:"tag"`
+	Value string `json:"value"`
+}
+
+// userMacro represent Zabbix user macro object, of hosts, templates or global
+// https://www.zabbix.com/documentation/current/manual/api/reference/usermacro/object
+type userMacro struct {
+	HostMacroID   string  `json:"hostmacroid,omitempty"`
+	GlobalMacroID string  `json:"globalmacroid,omitempty"`
+	HostID        string  `json:"hostid,omitempty"`
+	Macro         string  `json:"macro"`
+	Value         string  `json:"value"`
+	Description   *string `json:"description,omitempty"`
+	Type          string  `json:"type,omitempty"`
+}
+
+func templatesGet(api *zabbix.API, params zabbix.Params) (res []templateObject, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("template.get", params, &res)
 	return
 }
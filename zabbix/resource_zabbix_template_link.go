@@ -1,10 +1,13 @@
 package zabbix
 
 import (
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/claranet/go-zabbix-api"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 )
 
 func resourceZabbixTemplateLink() *schema.Resource {
@@ -17,10 +20,19 @@ func resourceZabbixTemplateLink() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		CustomizeDiff: resourceZabbixTemplateLinkCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"template_id": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
+				ForceNew: true,
+			},
+			"destroy_behavior": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "leave",
+				Description:  "Leave the objects of the template not managed by Terraform on destroy, or delete them.",
+				ValidateFunc: validation.StringInSlice([]string{"leave", "delete_unmanaged"}, false),
 			},
 			"item": &schema.Schema{
 				Type:     schema.TypeSet,
@@ -37,6 +49,35 @@ func resourceZabbixTemplateLink() *schema.Resource {
 				Elem:     schemaTemplatelldRule(),
 				Optional: true,
 			},
+			"graph": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     schemaTemplateGraph(),
+				Optional: true,
+			},
+			"server_item": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        schemaTemplateServerObject("item_id"),
+				Computed:    true,
+				Description: "Items of the template not tracked by the link, deleted on apply.",
+			},
+			"server_trigger": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        schemaTemplateServerObject("trigger_id"),
+				Computed:    true,
+				Description: "Triggers of the template not tracked by the link, deleted on apply.",
+			},
+			"server_lld_rule": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        schemaTemplateServerObject("lld_rule_id"),
+				Computed:    true,
+				Description: "LLD rules of the template not tracked by the link, deleted on apply.",
+			},
+			"server_graph": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        schemaTemplateServerObject("graph_id"),
+				Computed:    true,
+				Description: "Graphs of the template not tracked by the link, deleted on apply.",
+			},
 		},
 	}
 }
@@ -86,51 +127,126 @@ func schemaTemplatelldRule() *schema.Resource {
 	}
 }
 
+func schemaTemplateGraph() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"local": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"graph_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+		},
+	}
+}
+
+func schemaTemplateServerObject(idKey string) *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			idKey: &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// templateLinkObject is an object defined on the template, or on the
+// discovery rule of a zabbix_lld_rule_link
+type templateLinkObject struct {
+	ID   string
+	Name string
+}
+
+// templateLinkObjectType describes how to read and delete the objects of the
+// template or discovery rule tracked by a link
+type templateLinkObjectType struct {
+	attribute       string
+	serverAttribute string
+	idKey           string
+	deleteMethod    string
+	deleteIDKey     string
+	get             func(api *zabbix.API, parentID string) ([]templateLinkObject, error)
+}
+
+// templateLinkObjectTypes are ordered so that triggers and graphs are deleted
+// before the items they depend on
+var templateLinkObjectTypes = []templateLinkObjectType{
+	{
+		attribute:       "trigger",
+		serverAttribute: "server_trigger",
+		idKey:           "trigger_id",
+		deleteMethod:    "trigger.delete",
+		deleteIDKey:     "triggerids",
+		get:             getTemplateLinkTriggers,
+	},
+	{
+		attribute:       "graph",
+		serverAttribute: "server_graph",
+		idKey:           "graph_id",
+		deleteMethod:    "graph.delete",
+		deleteIDKey:     "graphids",
+		get:             getTemplateLinkGraphs,
+	},
+	{
+		attribute:       "lld_rule",
+		serverAttribute: "server_lld_rule",
+		idKey:           "lld_rule_id",
+		deleteMethod:    "discoveryrule.delete",
+		deleteIDKey:     "itemids",
+		get:             getTemplateLinkLLDRules,
+	},
+	{
+		attribute:       "item",
+		serverAttribute: "server_item",
+		idKey:           "item_id",
+		deleteMethod:    "item.delete",
+		deleteIDKey:     "itemids",
+		get:             getTemplateLinkItems,
+	},
+}
+
 func resourceZabbixTemplateLinkCreate(d *schema.ResourceData, meta interface{}) error {
+	d.SetId(d.Get("template_id").(string))
 	return resourceZabbixTemplateLinkRead(d, meta)
 }
 
 func resourceZabbixTemplateLinkRead(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
-	itemsTerraform, err := getTerraformTemplateItems(d, api)
-	if err != nil {
-		return err
-	}
-	d.Set("item", itemsTerraform)
+	// the id is the only attribute known when importing
+	d.Set("template_id", d.Id())
+	return readLinkObjects(d, api, templateLinkObjectTypes)
+}
 
-	triggersTerraform, err := getTerraformTemplateTriggers(d, api)
-	if err != nil {
-		return err
-	}
-	d.Set("trigger", triggersTerraform)
+func resourceZabbixTemplateLinkExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	api := meta.(*providerMeta).api
 
-	lldRulesTerraform, err := getTerraformTemplateLLDRules(d, api)
+	_, err := api.TemplateGetByID(d.Id())
 	if err != nil {
-		return err
+		if strings.Contains(err.Error(), "Expected exactly one result") {
+			log.Printf("[DEBUG] Template with id %s doesn't exist", d.Id())
+			return false, nil
+		}
+		return false, err
 	}
-	d.Set("lld_rule", lldRulesTerraform)
-
-	d.SetId(d.Get("template_id").(string))
-	return nil
+	return true, nil
 }
 
-func resourceZabbixTemplateLinkExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	return true, nil
+func resourceZabbixTemplateLinkCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	return customizeDiffLinkObjects(d, templateLinkObjectTypes)
 }
 
 func resourceZabbixTemplateLinkUpdate(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
-	err := updateZabbixTemplateItems(d, api)
-	if err != nil {
-		return err
-	}
-	err = updateZabbixTemplateTriggers(d, api)
-	if err != nil {
-		return err
-	}
-	err = updateZabbixTemplateDiscoveryRules(d, api)
+	err := updateLinkObjects(d, api, templateLinkObjectTypes)
 	if err != nil {
 		return err
 	}
@@ -138,250 +254,207 @@ func resourceZabbixTemplateLinkUpdate(d *schema.ResourceData, meta interface{})
 }
 
 func resourceZabbixTemplateLinkDelete(d *schema.ResourceData, meta interface{}) error {
-	return nil
-}
-
-func getTerraformTemplateItems(d *schema.ResourceData, api *zabbix.API) ([]interface{}, error) {
-	params := zabbix.Params{
-		"output": "extend",
-		"templateids": []string{
-			d.Get("template_id").(string),
-		},
-		"inherited": false,
-	}
-	items, err := api.ItemsGet(params)
-	if err != nil {
-		return nil, err
-	}
+	api := meta.(*providerMeta).api
 
-	itemsTerraform := make([]interface{}, len(items))
-	for i, item := range items {
-		var itemTerraform = make(map[string]interface{})
-
-		itemTerraform["local"] = true
-		itemTerraform["item_id"] = item.ItemID
-		itemsTerraform[i] = itemTerraform
-	}
-	return itemsTerraform, nil
+	return deleteUnmanagedLinkObjects(d, api, templateLinkObjectTypes)
 }
 
-func getTerraformTemplateTriggers(d *schema.ResourceData, api *zabbix.API) ([]interface{}, error) {
-	params := zabbix.Params{
-		"output": "extend",
-		"templateids": []string{
-			d.Get("template_id").(string),
-		},
-		"inherited": false,
-	}
-	triggers, err := api.TriggersGet(params)
-	if err != nil {
-		return nil, err
-	}
-
-	triggersTerraform := make([]interface{}, len(triggers))
-	for i, trigger := range triggers {
-		var triggerTerraform = make(map[string]interface{})
-
-		triggerTerraform["local"] = true
-		triggerTerraform["trigger_id"] = trigger.TriggerID
-		triggersTerraform[i] = triggerTerraform
+// deleteUnmanagedLinkObjects deletes the objects not tracked by the link when
+// it is destroyed with the delete_unmanaged behavior.
+func deleteUnmanagedLinkObjects(d *schema.ResourceData, api *zabbix.API, objectTypes []templateLinkObjectType) error {
+	if d.Get("destroy_behavior").(string) != "delete_unmanaged" {
+		return nil
 	}
-	return triggersTerraform, nil
-}
-
-func getTerraformTemplateLLDRules(d *schema.ResourceData, api *zabbix.API) ([]interface{}, error) {
-	params := zabbix.Params{
-		"output": "extend",
-		"templateids": []string{
-			d.Get("template_id").(string),
-		},
-		"inherited": false,
-	}
-	lldRules, err := api.DiscoveryRulesGet(params)
-	if err != nil {
-		return nil, err
-	}
-
-	lldRulesTerraform := make([]interface{}, len(lldRules))
-	for i, lldRule := range lldRules {
-		var lldRuleTerraform = make(map[string]interface{})
-
-		lldRuleTerraform["local"] = true
-		lldRuleTerraform["lld_rule_id"] = lldRule.ItemID
-		lldRulesTerraform[i] = lldRuleTerraform
+	for _, objectType := range objectTypes {
+		objects, err := objectType.get(api, d.Id())
+		if err != nil {
+			return err
+		}
+		_, server := readTemplateLinkObjects(d, objectType, objects)
+		err = deleteTemplateLinkObjects(d, api, objectType, server)
+		if err != nil {
+			return err
+		}
 	}
-	return lldRulesTerraform, nil
+	return nil
 }
 
-func updateZabbixTemplateItems(d *schema.ResourceData, api *zabbix.API) error {
-	if d.HasChange("item") {
-		oldV, newV := d.GetChange("item")
-		oldItems := oldV.(*schema.Set).List()
-		newItems := newV.(*schema.Set).List()
-		var deletedItems []string
-		templatedItems, err := api.ItemsGet(zabbix.Params{
-			"templateids": []string{
-				d.Get("template_id").(string),
-			},
-			"inherited": true,
-		})
-
+// readLinkObjects sets the objects tracked by the link and the ones only known
+// by the server.
+func readLinkObjects(d *schema.ResourceData, api *zabbix.API, objectTypes []templateLinkObjectType) error {
+	for _, objectType := range objectTypes {
+		objects, err := objectType.get(api, d.Id())
 		if err != nil {
 			return err
 		}
-		log.Printf("[DEBUG] Found templated item %#v", templatedItems)
-		for _, oldItem := range oldItems {
-			oldItemValue := oldItem.(map[string]interface{})
-			exist := false
+		tracked, server := readTemplateLinkObjects(d, objectType, objects)
+		d.Set(objectType.attribute, tracked)
+		d.Set(objectType.serverAttribute, server)
+	}
+	return nil
+}
 
-			if oldItemValue["local"] == true {
-				continue
-			}
+// customizeDiffLinkObjects plans the deletion of the server objects found by
+// the last refresh. The objects the configuration starts tracking are kept in
+// the planned server set, so that the plan only removes the objects deleted on
+// apply.
+func customizeDiffLinkObjects(d *schema.ResourceDiff, objectTypes []templateLinkObjectType) error {
+	for _, objectType := range objectTypes {
+		server := d.Get(objectType.serverAttribute).(*schema.Set).List()
+		if len(server) == 0 {
+			continue
+		}
 
-			for _, newItem := range newItems {
-				newItemValue := newItem.(map[string]interface{})
-				if newItemValue["item_id"].(string) == oldItemValue["item_id"].(string) {
-					exist = true
-				}
-			}
+		trackedIDs := map[string]bool{}
+		for _, value := range d.Get(objectType.attribute).(*schema.Set).List() {
+			trackedIDs[value.(map[string]interface{})[objectType.idKey].(string)] = true
+		}
 
-			if !exist {
-				templated := false
-
-				for _, templatedItem := range templatedItems {
-					if templatedItem.ItemID == oldItemValue["item_id"].(string) {
-						templated = true
-						break
-					}
-				}
-				if !templated {
-					deletedItems = append(deletedItems, oldItemValue["item_id"].(string))
-				}
+		kept := []interface{}{}
+		for _, value := range server {
+			if trackedIDs[value.(map[string]interface{})[objectType.idKey].(string)] {
+				kept = append(kept, value)
 			}
 		}
-		if len(deletedItems) > 0 {
-			log.Printf("[DEBUG] template link will delete item with ids : %#v", deletedItems)
-			_, err := api.ItemsDeleteIDs(deletedItems)
-			if err != nil {
-				return err
-			}
+		if len(kept) == len(server) {
+			continue
+		}
+		if err := d.SetNew(objectType.serverAttribute, kept); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
-func updateZabbixTemplateTriggers(d *schema.ResourceData, api *zabbix.API) error {
-	if d.HasChange("trigger") {
-		oldV, newV := d.GetChange("trigger")
-		oldTriggers := oldV.(*schema.Set).List()
-		newTriggers := newV.(*schema.Set).List()
-		var deletedTriggers []string
-		templatedTriggers, err := api.TriggersGet(zabbix.Params{
-			"output": "extend",
-			"templateids": []string{
-				d.Get("template_id").(string),
-			},
-			"inherited": true,
-		})
-
+// updateLinkObjects deletes the server objects found by the last refresh,
+// unless they are now tracked by the link.
+func updateLinkObjects(d *schema.ResourceData, api *zabbix.API, objectTypes []templateLinkObjectType) error {
+	for _, objectType := range objectTypes {
+		oldV, _ := d.GetChange(objectType.serverAttribute)
+		err := deleteTemplateLinkObjects(d, api, objectType, oldV.(*schema.Set).List())
 		if err != nil {
 			return err
 		}
-		log.Printf("[DEBUG] found templated trigger %#v", templatedTriggers)
-		for _, oldTrigger := range oldTriggers {
-			oldTriggerValue := oldTrigger.(map[string]interface{})
-			exist := false
-
-			if oldTriggerValue["local"] == true {
-				continue
-			}
+	}
+	return nil
+}
 
-			for _, newTrigger := range newTriggers {
-				newTriggerValue := newTrigger.(map[string]interface{})
-				if oldTriggerValue["trigger_id"].(string) == newTriggerValue["trigger_id"].(string) {
-					exist = true
-				}
-			}
+// readTemplateLinkObjects splits the objects of the template between the ones
+// tracked by the link and the ones only known by the server.
+func readTemplateLinkObjects(d *schema.ResourceData, objectType templateLinkObjectType, objects []templateLinkObject) (tracked []interface{}, server []interface{}) {
+	trackedIDs := map[string]bool{}
+	for _, value := range d.Get(objectType.attribute).(*schema.Set).List() {
+		trackedIDs[value.(map[string]interface{})[objectType.idKey].(string)] = true
+	}
 
-			if !exist {
-				templated := false
-
-				for _, templatedTrigger := range templatedTriggers {
-					if templatedTrigger.TriggerID == oldTriggerValue["trigger_id"].(string) {
-						templated = true
-						break
-					}
-				}
-				if !templated {
-					deletedTriggers = append(deletedTriggers, oldTriggerValue["trigger_id"].(string))
-				}
-			}
+	tracked = []interface{}{}
+	server = []interface{}{}
+	for _, object := range objects {
+		if trackedIDs[object.ID] {
+			tracked = append(tracked, map[string]interface{}{
+				"local":          true,
+				objectType.idKey: object.ID,
+			})
+		} else {
+			server = append(server, map[string]interface{}{
+				objectType.idKey: object.ID,
+				"name":           object.Name,
+			})
 		}
-		if len(deletedTriggers) > 0 {
-			log.Printf("[DEBUG] template link will delete trigger with ids : %#v", deletedTriggers)
-			_, err := api.TriggersDeleteIDs(deletedTriggers)
-			if err != nil {
-				return err
-			}
+	}
+	return
+}
+
+// deleteTemplateLinkObjects deletes the server objects, unless they are now
+// tracked by the link.
+func deleteTemplateLinkObjects(d *schema.ResourceData, api *zabbix.API, objectType templateLinkObjectType, server []interface{}) error {
+	trackedIDs := map[string]bool{}
+	for _, value := range d.Get(objectType.attribute).(*schema.Set).List() {
+		trackedIDs[value.(map[string]interface{})[objectType.idKey].(string)] = true
+	}
+
+	var deletedIDs []string
+	for _, value := range server {
+		id := value.(map[string]interface{})[objectType.idKey].(string)
+		if !trackedIDs[id] {
+			deletedIDs = append(deletedIDs, id)
 		}
 	}
+	if len(deletedIDs) == 0 {
+		return nil
+	}
+
+	log.Printf("[DEBUG] link will delete %s with ids : %#v", objectType.attribute, deletedIDs)
+	err := callDeleteByIDs(api, objectType.deleteMethod, deletedIDs, objectType.deleteIDKey)
+	if err != nil {
+		return fmt.Errorf("%s, deleting %s %v", err.Error(), objectType.attribute, deletedIDs)
+	}
 	return nil
 }
 
-func updateZabbixTemplateDiscoveryRules(d *schema.ResourceData, api *zabbix.API) error {
-	if d.HasChange("lld_rule") {
-		oldV, newV := d.GetChange("lld_rule")
-		oldlldRules := oldV.(*schema.Set).List()
-		newlldRules := newV.(*schema.Set).List()
-		var deletedlldRules []string
-		templatedlldRules, err := api.DiscoveryRulesGet(zabbix.Params{
-			"output": "extend",
-			"templateids": []string{
-				d.Get("template_id").(string),
-			},
-			"inherited": true,
-		})
+func getTemplateLinkItems(api *zabbix.API, templateID string) ([]templateLinkObject, error) {
+	items, err := api.ItemsGet(zabbix.Params{
+		"output":      "extend",
+		"templateids": []string{templateID},
+		"inherited":   false,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		if err != nil {
-			return err
-		}
-		log.Printf("[DEBUG] found templated lldRule %#v", templatedlldRules)
-		for _, oldlldRule := range oldlldRules {
-			oldlldRuleValue := oldlldRule.(map[string]interface{})
-			exist := false
+	objects := make([]templateLinkObject, len(items))
+	for i, item := range items {
+		objects[i] = templateLinkObject{ID: item.ItemID, Name: item.Name}
+	}
+	return objects, nil
+}
 
-			if oldlldRuleValue["local"] == true {
-				continue
-			}
+func getTemplateLinkTriggers(api *zabbix.API, templateID string) ([]templateLinkObject, error) {
+	triggers, err := api.TriggersGet(zabbix.Params{
+		"output":      "extend",
+		"templateids": []string{templateID},
+		"inherited":   false,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-			for _, newlldRule := range newlldRules {
-				newlldRuleValue := newlldRule.(map[string]interface{})
-				if oldlldRuleValue["lld_rule_id"].(string) == newlldRuleValue["lld_rule_id"].(string) {
-					exist = true
-				}
-			}
+	objects := make([]templateLinkObject, len(triggers))
+	for i, trigger := range triggers {
+		objects[i] = templateLinkObject{ID: trigger.TriggerID, Name: trigger.Description}
+	}
+	return objects, nil
+}
 
-			if !exist {
-				templated := false
-
-				for _, templatedlldRule := range templatedlldRules {
-					if templatedlldRule.ItemID == oldlldRuleValue["lld_rule_id"].(string) {
-						templated = true
-						break
-					}
-				}
-				if !templated {
-					deletedlldRules = append(deletedlldRules, oldlldRuleValue["lld_rule_id"].(string))
-				}
-			}
-		}
-		if len(deletedlldRules) > 0 {
-			log.Printf("[DEBUG] template link will delete lldRule with ids : %#v", deletedlldRules)
-			_, err := api.DiscoveryRulesDeletesIDs(deletedlldRules)
-			if err != nil {
-				return err
-			}
-		}
+func getTemplateLinkLLDRules(api *zabbix.API, templateID string) ([]templateLinkObject, error) {
+	lldRules, err := api.DiscoveryRulesGet(zabbix.Params{
+		"output":      "extend",
+		"templateids": []string{templateID},
+		"inherited":   false,
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+
+	objects := make([]templateLinkObject, len(lldRules))
+	for i, lldRule := range lldRules {
+		objects[i] = templateLinkObject{ID: lldRule.ItemID, Name: lldRule.Name}
+	}
+	return objects, nil
+}
+
+func getTemplateLinkGraphs(api *zabbix.API, templateID string) ([]templateLinkObject, error) {
+	graphs, err := graphsGet(api, "graph.get", zabbix.Params{
+		"templateids": []string{templateID},
+		"inherited":   false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]templateLinkObject, len(graphs))
+	for i, graph := range graphs {
+		objects[i] = templateLinkObject{ID: graph.GraphID, Name: graph.Name}
+	}
+	return objects, nil
 }
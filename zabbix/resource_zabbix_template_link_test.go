@@ -90,7 +90,7 @@ func TestAccZabbixTemplateLink_DeleteServerItem(t *testing.T) {
 				),
 			},
 			{
-				PreConfig: testAccZabbixTemplateLinkCreateServerItem(template, &item),
+				PreConfig: testAccZabbixTemplateLinkCreateServerItem(&template, &item),
 				Config:    testAccZabbixTemplateLinkConfig(groupName, templateName),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					testAccCheckTemplateServerItemDelete(&item),
@@ -136,7 +136,7 @@ func TestAccZabbixTemplateLink_DeleteServerTrigger(t *testing.T) {
 				),
 			},
 			{
-				PreConfig: testAccZabbixTemplateLinkCreateServerTrigger(template, item, &trigger),
+				PreConfig: testAccZabbixTemplateLinkCreateServerTrigger(&template, item, &trigger),
 				Config:    testAccZabbixTemplateLinkConfig(groupName, templateName),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					testAccCheckTemplateServerTriggerDelete(&trigger),
@@ -150,6 +150,84 @@ func TestAccZabbixTemplateLink_DeleteServerTrigger(t *testing.T) {
 	})
 }
 
+func TestAccZabbixTemplateLink_DeleteServerGraph(t *testing.T) {
+	strID := acctest.RandString(5)
+	groupName := fmt.Sprintf("host_group_%s", strID)
+	templateName := fmt.Sprintf("template_%s", strID)
+
+	var itemID, graphID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixTemplateLinkDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixTemplateLinkGraphConfig(groupName, templateName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckResourceID("zabbix_item.item_test_0", &itemID),
+					resource.TestCheckResourceAttr("zabbix_template_link.template_link_test", "graph.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_template_link.template_link_test", "server_graph.#", "0"),
+				),
+			},
+			{
+				PreConfig: testAccZabbixTemplateLinkCreateServerGraph(&itemID, &graphID),
+				Config:    testAccZabbixTemplateLinkGraphConfig(groupName, templateName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckServerObjectDelete("graph.get", "graphids", &graphID),
+					resource.TestCheckResourceAttr("zabbix_template_link.template_link_test", "graph.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_template_link.template_link_test", "server_graph.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccZabbixTemplateLink_DestroyUnmanaged(t *testing.T) {
+	strID := acctest.RandString(5)
+	groupName := fmt.Sprintf("host_group_%s", strID)
+	templateName := fmt.Sprintf("template_%s", strID)
+
+	var template zabbix.Template
+	item := zabbix.Item{
+		Name:  "server_item",
+		Key:   "server.key",
+		Type:  zabbix.ZabbixAgent,
+		Delay: "30",
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixTemplateLinkDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixTemplateLinkDestroyBehaviorConfig(groupName, templateName, true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckTemplateExists("zabbix_template.template_test", &template),
+					resource.TestCheckResourceAttr("zabbix_template_link.template_link_test", "destroy_behavior", "delete_unmanaged"),
+					resource.TestCheckResourceAttr("zabbix_template_link.template_link_test", "item.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_template_link.template_link_test", "server_item.#", "0"),
+				),
+			},
+			{
+				// the server item is listed by the refresh and its deletion planned
+				PreConfig:          testAccZabbixTemplateLinkCreateServerItem(&template, &item),
+				Config:             testAccZabbixTemplateLinkDestroyBehaviorConfig(groupName, templateName, true),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccZabbixTemplateLinkDestroyBehaviorConfig(groupName, templateName, false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckTemplateServerItemDelete(&item),
+					resource.TestCheckResourceAttrSet("zabbix_item.item_test_0", "id"),
+				),
+			},
+		},
+	})
+}
+
 func testAccZabbixTemplateLinkConfig(groupName, templateName string) string {
 	return fmt.Sprintf(`
 		data "zabbix_server" "test" {}
@@ -191,6 +269,44 @@ func testAccZabbixTemplateLinkConfig(groupName, templateName string) string {
 	`, groupName, templateName, templateName)
 }
 
+func testAccZabbixTemplateLinkGraphConfig(groupName, templateName string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "zabbix" {
+			name = "host group test %s"
+		}
+
+		resource "zabbix_template" "template_test" {
+			host = "%s"
+			groups = [ zabbix_host_group.zabbix.name ]
+		}
+
+		resource "zabbix_item" "item_test_0" {
+			name = "item_test_0"
+			key = "bilou.bilou"
+			delay = "34"
+			host_id = zabbix_template.template_test.id
+		}
+
+		resource "zabbix_graph" "graph_test_0" {
+			name = "graph_test_0"
+			graph_item {
+				item_id = zabbix_item.item_test_0.id
+				color   = "00AA00"
+			}
+		}
+
+		resource "zabbix_template_link" "template_link_test" {
+			template_id = zabbix_template.template_test.id
+			item {
+				item_id = zabbix_item.item_test_0.id
+			}
+			graph {
+				graph_id = zabbix_graph.graph_test_0.id
+			}
+		}
+	`, groupName, templateName)
+}
+
 func testAccZabbixTemplateLinkDeleteTrigger(groupName, templateName string) string {
 	return fmt.Sprintf(`
 		resource "zabbix_host_group" "zabbix" {
@@ -237,9 +353,42 @@ func testAccZabbixTemplateLinkDeleteItem(groupName, templateName string) string
 	`, groupName, templateName, templateName)
 }
 
-func testAccZabbixTemplateLinkCreateServerItem(template zabbix.Template, item *zabbix.Item) func() {
+func testAccZabbixTemplateLinkDestroyBehaviorConfig(groupName, templateName string, link bool) string {
+	config := fmt.Sprintf(`
+		resource "zabbix_host_group" "zabbix" {
+			name = "host group test %s"
+		}
+
+		resource "zabbix_template" "template_test" {
+			host = "%s"
+			groups = [zabbix_host_group.zabbix.name]
+			name = "display name for template test %s"
+		}
+
+		resource "zabbix_item" "item_test_0" {
+			name = "item_test_0"
+			key = "bilou.bilou"
+			delay = "34"
+			host_id = zabbix_template.template_test.id
+		}
+	`, groupName, templateName, templateName)
+	if link {
+		config += `
+		resource "zabbix_template_link" "template_link_test" {
+			template_id = zabbix_template.template_test.id
+			destroy_behavior = "delete_unmanaged"
+			item {
+				item_id = zabbix_item.item_test_0.id
+			}
+		}
+	`
+	}
+	return config
+}
+
+func testAccZabbixTemplateLinkCreateServerItem(template *zabbix.Template, item *zabbix.Item) func() {
 	return func() {
-		api := testAccProvider.Meta().(*zabbix.API)
+		api := testAccProvider.Meta().(*providerMeta).api
 
 		item.HostID = template.TemplateID
 		items := zabbix.Items{*item}
@@ -251,9 +400,9 @@ func testAccZabbixTemplateLinkCreateServerItem(template zabbix.Template, item *z
 	}
 }
 
-func testAccZabbixTemplateLinkCreateServerTrigger(template zabbix.Template, item zabbix.Item, trigger *zabbix.Trigger) func() {
+func testAccZabbixTemplateLinkCreateServerTrigger(template *zabbix.Template, item zabbix.Item, trigger *zabbix.Trigger) func() {
 	return func() {
-		api := testAccProvider.Meta().(*zabbix.API)
+		api := testAccProvider.Meta().(*providerMeta).api
 
 		trigger.Expression = fmt.Sprintf("{%s:%s.last()} = 0", template.Host, item.Key)
 		triggers := zabbix.Triggers{*trigger}
@@ -266,6 +415,27 @@ func testAccZabbixTemplateLinkCreateServerTrigger(template zabbix.Template, item
 	}
 }
 
+func testAccZabbixTemplateLinkCreateServerGraph(itemID, graphID *string) func() {
+	return func() {
+		api := testAccProvider.Meta().(*providerMeta).api
+
+		ids, err := callCreate(api, "graph.create", []map[string]interface{}{{
+			"name":   "server_graph",
+			"width":  900,
+			"height": 200,
+			"gitems": []map[string]interface{}{{
+				"itemid": *itemID,
+				"color":  "AA0000",
+			}},
+		}}, "graphids")
+		if err != nil {
+			log.Print(err)
+			return
+		}
+		*graphID = ids[0]
+	}
+}
+
 func testAccCheckZabbixTemplateLinkDestroy(s *terraform.State) error {
 	return nil
 }
@@ -277,19 +447,19 @@ func testAccCheckTemplateExists(n string, template *zabbix.Template) resource.Te
 			return fmt.Errorf("Not found: %s", n)
 		}
 
-		api := testAccProvider.Meta().(*zabbix.API)
+		api := testAccProvider.Meta().(*providerMeta).api
 		templates, err := api.TemplateGetByID(rs.Primary.ID)
 		if err != nil {
 			return err
 		}
-		template = templates
+		*template = *templates
 		return nil
 	}
 }
 
 func testAccCheckTemplateServerItemDelete(item *zabbix.Item) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		api := testAccProvider.Meta().(*zabbix.API)
+		api := testAccProvider.Meta().(*providerMeta).api
 
 		_, err := api.ItemGetByID(item.ItemID)
 		if err == nil {
@@ -306,7 +476,7 @@ func testAccCheckTemplateServerItemDelete(item *zabbix.Item) resource.TestCheckF
 
 func testAccCheckTemplateServerTriggerDelete(trigger *zabbix.Trigger) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		api := testAccProvider.Meta().(*zabbix.API)
+		api := testAccProvider.Meta().(*providerMeta).api
 
 		_, err := api.TriggerGetByID(trigger.TriggerID)
 		if err == nil {
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/claranet/go-zabbix-api"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
@@ -91,6 +90,70 @@ func TestAccZabbixTemplate_UserMacro(t *testing.T) {
 	})
 }
 
+func TestAccZabbixTemplate_UserMacroBlock(t *testing.T) {
+	resourceName := "zabbix_template.template_test"
+	strID := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixTemplateUserMacroAdd(strID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "macro.%", "2"),
+					resource.TestCheckResourceAttr(resourceName, "user_macro.#", "0"),
+				),
+			},
+			{
+				Config: testAccZabbixTemplateUserMacroBlock(strID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "macro.%", "0"),
+					resource.TestCheckResourceAttr(resourceName, "user_macro.#", "3"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				// the value of the secret macro is not returned by Zabbix
+				ImportStateVerifyIgnore: []string{"user_macro"},
+			},
+		},
+	})
+}
+
+func TestAccZabbixTemplate_Tags(t *testing.T) {
+	resourceName := "zabbix_template.template_test"
+	strID := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixTemplateTags(strID, "database"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "tag.#", "2"),
+				),
+			},
+			{
+				Config: testAccZabbixTemplateTags(strID, "web"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "tag.#", "2"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
 func TestAccZabbixTemplate_linkedTemplate(t *testing.T) {
 	resource1Name := "zabbix_template.template_test_1"
 	resource2Name := "zabbix_template.template_test_2"
@@ -126,7 +189,7 @@ func TestAccZabbixTemplate_linkedTemplate(t *testing.T) {
 }
 
 func testAccCheckZabbixTemplateDestroy(s *terraform.State) error {
-	api := testAccProvider.Meta().(*zabbix.API)
+	api := testAccProvider.Meta().(*providerMeta).api
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "zabbix_template" {
@@ -285,3 +348,55 @@ func testAccZabbixTemplateUserMacroDelete(strID string) string {
 	}
 	`, strID, strID)
 }
+
+func testAccZabbixTemplateUserMacroBlock(strID string) string {
+	return fmt.Sprintf(`
+	resource "zabbix_host_group" "host_group_test" {
+		name = "host_group_%s"
+	}
+
+	resource "zabbix_template" "template_test" {
+		host = "template_%s"
+		groups = ["${zabbix_host_group.host_group_test.name}"]
+
+		user_macro {
+			name  = "MYMACRO1"
+			value = "value1"
+		}
+
+		user_macro {
+			name        = "{$MYMACRO2}"
+			value       = "value2"
+			description = "Macro with a description"
+		}
+
+		user_macro {
+			name  = "PASSWORD"
+			value = "secret_value"
+			type  = "secret"
+		}
+	}
+	`, strID, strID)
+}
+
+func testAccZabbixTemplateTags(strID string, service string) string {
+	return fmt.Sprintf(`
+	resource "zabbix_host_group" "host_group_test" {
+		name = "host_group_%s"
+	}
+
+	resource "zabbix_template" "template_test" {
+		host = "template_%s"
+		groups = ["${zabbix_host_group.host_group_test.name}"]
+
+		tag {
+			tag   = "service"
+			value = "%s"
+		}
+
+		tag {
+			tag = "team-ops"
+		}
+	}
+	`, strID, strID, service)
+}
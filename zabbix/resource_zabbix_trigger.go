@@ -3,12 +3,51 @@ package zabbix
 import (
 	"fmt"
 	"log"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/claranet/go-zabbix-api"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 )
 
+// TriggerPriorities zabbix different severities of triggers and trigger
+// prototypes
+var TriggerPriorities = map[string]int{
+	"not_classified": 0,
+	"information":    1,
+	"warning":        2,
+	"average":        3,
+	"high":           4,
+	"disaster":       5,
+}
+
+// TriggerStatuses zabbix different statuses of triggers and trigger prototypes
+var TriggerStatuses = map[string]int{
+	"enabled":  0,
+	"disabled": 1,
+}
+
+// TriggerRecoveryModes zabbix different ways to generate OK events
+var TriggerRecoveryModes = map[string]int{
+	"expression":          0,
+	"recovery_expression": 1,
+	"none":                2,
+}
+
+// TriggerCorrelationModes zabbix different ways to close problems on OK events
+var TriggerCorrelationModes = map[string]int{
+	"all": 0,
+	"tag": 1,
+}
+
+// TriggerTypes zabbix different problem event generation of triggers
+var TriggerTypes = map[string]int{
+	"single":   0,
+	"multiple": 1,
+}
+
 func resourceZabbixTrigger() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceZabbixTriggerCreate,
@@ -25,36 +64,30 @@ func resourceZabbixTrigger() *schema.Resource {
 				Required: true,
 			},
 			"expression": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "Expression of the trigger, the syntax before Zabbix 5.4 is converted on newer servers.",
+				DiffSuppressFunc: suppressTriggerExpressionDiff,
 			},
 			"comment": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
 			},
 			"priority": &schema.Schema{
-				Type:     schema.TypeInt,
-				Optional: true,
-				Default:  0,
-				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
-					v := val.(int)
-					if v < 0 || v > 5 {
-						errs = append(errs, fmt.Errorf("%q, must be between 0 and 5 inclusive, got %d", key, v))
-					}
-					return
-				},
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "not_classified",
+				Description:  "Severity of the trigger, its name or its value.",
+				ValidateFunc: validateEnum(TriggerPriorities),
+				StateFunc:    enumStateFunc(TriggerPriorities),
 			},
 			"status": &schema.Schema{
-				Type:     schema.TypeInt,
-				Optional: true,
-				Default:  0,
-				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
-					v := val.(int)
-					if v < 0 || v > 1 {
-						errs = append(errs, fmt.Errorf("%q, must be between 0 and 1 inclusive, got %d", key, v))
-					}
-					return
-				},
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "enabled",
+				Description:  "Status of the trigger, its name or its value.",
+				ValidateFunc: validateEnum(TriggerStatuses),
+				StateFunc:    enumStateFunc(TriggerStatuses),
 			},
 			"dependencies": &schema.Schema{
 				Type:        schema.TypeSet,
@@ -62,27 +95,87 @@ func resourceZabbixTrigger() *schema.Resource {
 				Optional:    true,
 				Description: "ID of the trigger it depands",
 			},
+			"recovery_mode": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "expression",
+				Description:  "OK event generation mode.",
+				ValidateFunc: validation.StringInSlice([]string{"expression", "recovery_expression", "none"}, false),
+			},
+			"recovery_expression": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Expression generating OK events, used by the recovery_expression recovery mode.",
+				DiffSuppressFunc: suppressTriggerExpressionDiff,
+			},
+			"correlation_mode": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "all",
+				Description:  "Problems closed by OK events.",
+				ValidateFunc: validation.StringInSlice([]string{"all", "tag"}, false),
+			},
+			"correlation_tag": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Tag matching the problems to close, used by the tag correlation mode.",
+			},
+			"manual_close": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "single",
+				Description:  "Whether the trigger generates a single or multiple problem events.",
+				ValidateFunc: validation.StringInSlice([]string{"single", "multiple"}, false),
+			},
+			"url": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"event_name": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Name of the problem events, only supported on Zabbix 5.4+.",
+			},
+			"opdata": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Operational data of the problems, only supported on Zabbix 4.4+.",
+			},
+			"tag": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     schemaHostTag(),
+				Optional: true,
+			},
 		},
 	}
 }
 
 func resourceZabbixTriggerCreate(d *schema.ResourceData, meta interface{}) error {
-	trigger := createTriggerObj(d)
+	trigger, err := createTriggerObj(d, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
 
-	return createRetry(d, meta, createTrigger, trigger, resourceZabbixTriggerRead)
+	return createRetry(d, meta, createTrigger, *trigger, resourceZabbixTriggerRead)
 }
 
 func resourceZabbixTriggerRead(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	params := zabbix.Params{
 		"output":             "extend",
 		"selectDependencies": "extend",
 		"selectFunctions":    "extend",
 		"selectItems":        "extend",
+		"selectTags":         "extend",
 		"triggerids":         d.Id(),
 	}
-	res, err := api.TriggersGet(params)
+	res, err := triggersGet(api, params)
 	if err != nil {
 		return err
 	}
@@ -90,30 +183,56 @@ func resourceZabbixTriggerRead(d *schema.ResourceData, meta interface{}) error {
 		return fmt.Errorf("Expected one result got : %d", len(res))
 	}
 	trigger := res[0]
-	err = getTriggerExpression(&trigger, api)
+	err = getTriggerExpression(&trigger, api, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
 	log.Printf("[DEBUG] trigger expression: %s", trigger.Expression)
 	d.Set("description", trigger.Description)
 	d.Set("expression", trigger.Expression)
+	d.Set("recovery_expression", trigger.RecoveryExpression)
 	if trigger.Comments != "" {
 		d.Set("comment", trigger.Comments)
 	}
-	d.Set("priority", trigger.Priority)
-	if trigger.Status != 0 {
-		d.Set("status", trigger.Status)
-	} else {
-		d.Set("value", 0)
-	}
+	d.Set("priority", strconv.Itoa(int(trigger.Priority)))
+	d.Set("status", strconv.Itoa(int(trigger.Status)))
 
 	var dependencies []string
 	for _, dependencie := range trigger.Dependencies {
 		dependencies = append(dependencies, dependencie.TriggerID)
 	}
 	d.Set("dependencies", dependencies)
+
+	for name, mode := range TriggerRecoveryModes {
+		if strconv.Itoa(mode) == trigger.RecoveryMode {
+			d.Set("recovery_mode", name)
+		}
+	}
+	for name, mode := range TriggerCorrelationModes {
+		if strconv.Itoa(mode) == trigger.CorrelationMode {
+			d.Set("correlation_mode", name)
+		}
+	}
+	d.Set("correlation_tag", trigger.CorrelationTag)
+	d.Set("manual_close", trigger.ManualClose == "1")
+	for name, triggerType := range TriggerTypes {
+		if strconv.Itoa(triggerType) == trigger.Type {
+			d.Set("type", name)
+		}
+	}
+	d.Set("url", trigger.URL)
+	if trigger.EventName != nil {
+		d.Set("event_name", *trigger.EventName)
+	}
+	if trigger.Opdata != nil {
+		d.Set("opdata", *trigger.Opdata)
+	}
+	d.Set("tag", readHostTags(trigger.Tags))
 	return nil
 }
 
 func resourceZabbixTriggerExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	_, err := api.TriggerGetByID(d.Id())
 	if err != nil {
@@ -127,17 +246,20 @@ func resourceZabbixTriggerExists(d *schema.ResourceData, meta interface{}) (bool
 }
 
 func resourceZabbixTriggerUpdate(d *schema.ResourceData, meta interface{}) error {
-	trigger := createTriggerObj(d)
+	trigger, err := createTriggerObj(d, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
 
 	trigger.TriggerID = d.Id()
 	if !d.HasChange("dependencies") {
 		trigger.Dependencies = nil
 	}
-	return createRetry(d, meta, updateTrigger, trigger, resourceZabbixTriggerRead)
+	return createRetry(d, meta, updateTrigger, *trigger, resourceZabbixTriggerRead)
 }
 
 func resourceZabbixTriggerDelete(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	return deleteRetry(d.Id(), getTriggerParentID, api.TriggersDeleteIDs, api)
 }
@@ -153,18 +275,65 @@ func createTriggerDependencies(d *schema.ResourceData) zabbix.Triggers {
 	return dependencies
 }
 
-func createTriggerObj(d *schema.ResourceData) zabbix.Trigger {
-	return zabbix.Trigger{
-		Description:  d.Get("description").(string),
-		Expression:   d.Get("expression").(string),
-		Comments:     d.Get("comment").(string),
-		Priority:     zabbix.SeverityType(d.Get("priority").(int)),
-		Status:       zabbix.StatusType(d.Get("status").(int)),
-		Dependencies: createTriggerDependencies(d),
+func createTriggerObj(d *schema.ResourceData, zabbixVersion string) (*triggerObject, error) {
+	expression, err := createTriggerExpression(d.Get("expression").(string), zabbixVersion)
+	if err != nil {
+		return nil, err
+	}
+	recoveryExpression, err := createTriggerExpression(d.Get("recovery_expression").(string), zabbixVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	trigger := triggerObject{
+		Trigger: zabbix.Trigger{
+			Description:  d.Get("description").(string),
+			Expression:   expression,
+			Comments:     d.Get("comment").(string),
+			Priority:     zabbix.SeverityType(enumToInt(TriggerPriorities, d.Get("priority").(string))),
+			Status:       zabbix.StatusType(enumToInt(TriggerStatuses, d.Get("status").(string))),
+			Dependencies: createTriggerDependencies(d),
+		},
+		RecoveryMode:       strconv.Itoa(TriggerRecoveryModes[d.Get("recovery_mode").(string)]),
+		RecoveryExpression: recoveryExpression,
+		CorrelationMode:    strconv.Itoa(TriggerCorrelationModes[d.Get("correlation_mode").(string)]),
+		CorrelationTag:     d.Get("correlation_tag").(string),
+		ManualClose:        boolToString(d.Get("manual_close").(bool)),
+		Type:               strconv.Itoa(TriggerTypes[d.Get("type").(string)]),
+		URL:                d.Get("url").(string),
+		Tags:               createTags(d),
+	}
+
+	if d.Get("recovery_mode").(string) == "recovery_expression" {
+		if trigger.RecoveryExpression == "" {
+			return nil, fmt.Errorf("recovery_expression is required by the recovery_expression recovery mode")
+		}
+	} else if trigger.RecoveryExpression != "" {
+		return nil, fmt.Errorf("recovery_expression is only used by the recovery_expression recovery mode")
+	}
+	if d.Get("correlation_mode").(string) == "tag" && trigger.CorrelationTag == "" {
+		return nil, fmt.Errorf("correlation_tag is required by the tag correlation mode")
+	}
+
+	eventName := d.Get("event_name").(string)
+	if isZabbixServerVersion54OrHigher(zabbixVersion) {
+		trigger.EventName = &eventName
+	} else if eventName != "" {
+		return nil, fmt.Errorf("event_name is only supported from Zabbix 5.4")
 	}
+	opdata := d.Get("opdata").(string)
+	if isZabbixServerVersion44OrHigher(zabbixVersion) {
+		trigger.Opdata = &opdata
+	} else if opdata != "" {
+		return nil, fmt.Errorf("opdata is only supported from Zabbix 4.4")
+	}
+
+	return &trigger, nil
 }
 
-func getTriggerExpression(trigger *zabbix.Trigger, api *zabbix.API) error {
+// getTriggerExpression replaces the function IDs of the problem and recovery
+// expressions of a trigger with the host, key and parameters of the function.
+func getTriggerExpression(trigger *triggerObject, api *zabbix.API, zabbixVersion string) error {
 	for _, function := range trigger.Functions {
 		var item zabbix.Item
 
@@ -184,12 +353,166 @@ func getTriggerExpression(trigger *zabbix.Trigger, api *zabbix.API) error {
 			return fmt.Errorf("Expected one parent host for item with id %s, and got : %d", function.ItemID, len(item.ItemParent))
 		}
 		idstr := fmt.Sprintf("{%s}", function.FunctionID)
-		expendValue := fmt.Sprintf("{%s:%s.%s(%s)}", item.ItemParent[0].Host, item.Key, function.Function, function.Parameter)
+		expendValue := expandTriggerFunction(item.ItemParent[0].Host, item.Key, function.Function, function.Parameter, zabbixVersion)
 		trigger.Expression = strings.Replace(trigger.Expression, idstr, expendValue, 1)
+		trigger.RecoveryExpression = strings.Replace(trigger.RecoveryExpression, idstr, expendValue, 1)
 	}
 	return nil
 }
 
+// expandTriggerFunction returns a function of a trigger expression in the
+// syntax of the server, func(/host/key,params) since Zabbix 5.4 and
+// {host:key.func(params)} before. Since Zabbix 5.4 the first parameter of
+// the functions returned by the API is $, standing for the item.
+func expandTriggerFunction(host, key, function, parameter, zabbixVersion string) string {
+	if !isZabbixServerVersion54OrHigher(zabbixVersion) {
+		return fmt.Sprintf("{%s:%s.%s(%s)}", host, key, function, parameter)
+	}
+
+	item := fmt.Sprintf("/%s/%s", host, key)
+	if strings.HasPrefix(parameter, "$") {
+		return fmt.Sprintf("%s(%s%s)", function, item, strings.TrimPrefix(parameter, "$"))
+	}
+	if parameter != "" {
+		return fmt.Sprintf("%s(%s,%s)", function, item, parameter)
+	}
+	return fmt.Sprintf("%s(%s)", function, item)
+}
+
+// oldTriggerFunctionRegexp matches the {host:key.func(params)} functions of
+// expressions before Zabbix 5.4, quoted parameters may contain ] and )
+var oldTriggerFunctionRegexp = regexp.MustCompile(`\{([^:{}]+):([\w.\-]+(?:\[(?:"(?:[^"\\]|\\.)*"|[^\]"])*\])?)\.(\w+)\(((?:"(?:[^"\\]|\\.)*"|[^)"])*)\)\}`)
+
+// triggerFunctionsChangedIn54 are the functions renamed or whose parameters
+// were reordered by Zabbix 5.4, they cannot be converted by
+// normalizeTriggerExpression
+var triggerFunctionsChangedIn54 = map[string]bool{
+	"abschange":  true,
+	"band":       true,
+	"date":       true,
+	"dayofmonth": true,
+	"dayofweek":  true,
+	"delta":      true,
+	"diff":       true,
+	"iregexp":    true,
+	"now":        true,
+	"prev":       true,
+	"regexp":     true,
+	"str":        true,
+	"strlen":     true,
+	"time":       true,
+}
+
+// triggerFunctionsWithTimeShift are the functions whose second parameter was a
+// time shift before Zabbix 5.4, it is now appended to the period like
+// 5m:now-1h
+var triggerFunctionsWithTimeShift = map[string]bool{
+	"avg":        true,
+	"forecast":   true,
+	"last":       true,
+	"max":        true,
+	"min":        true,
+	"percentile": true,
+	"sum":        true,
+	"timeleft":   true,
+}
+
+// normalizeTriggerExpression converts the functions of expressions written
+// for Zabbix before 5.4 to the func(/host/key,params) syntax. The period
+// ignored by last is dropped and the time shifts are merged into the periods,
+// the functions renamed by Zabbix 5.4, like str or diff, and count with more
+// than one parameter return an error.
+func normalizeTriggerExpression(expression string) (string, error) {
+	var err error
+	normalized := oldTriggerFunctionRegexp.ReplaceAllStringFunc(expression, func(function string) string {
+		match := oldTriggerFunctionRegexp.FindStringSubmatch(function)
+		parameter, functionErr := normalizeTriggerFunctionParameter(match[3], match[4])
+		if functionErr != nil {
+			if err == nil {
+				err = fmt.Errorf("%s: %s", function, functionErr)
+			}
+			return function
+		}
+		return expandTriggerFunction(match[1], match[2], match[3], parameter, "5.4.0")
+	})
+	return normalized, err
+}
+
+// normalizeTriggerFunctionParameter converts the parameters of a function
+// written for Zabbix before 5.4.
+func normalizeTriggerFunctionParameter(function, parameter string) (string, error) {
+	if triggerFunctionsChangedIn54[function] {
+		return "", fmt.Errorf("%s was changed by Zabbix 5.4 and cannot be converted, use the func(/host/key,params) syntax", function)
+	}
+
+	parameters := splitTriggerFunctionParameters(parameter)
+	if function == "count" && len(parameters) > 1 {
+		return "", fmt.Errorf("the parameters of count were reordered by Zabbix 5.4 and cannot be converted, use the count(/host/key,params) syntax")
+	}
+	if !triggerFunctionsWithTimeShift[function] {
+		return parameter, nil
+	}
+
+	// last only used the #num period, last(0) was the latest value
+	if function == "last" && !strings.HasPrefix(parameters[0], "#") {
+		parameters[0] = ""
+	}
+	if len(parameters) > 1 {
+		if timeShift := strings.TrimSpace(parameters[1]); timeShift != "" {
+			if parameters[0] == "" {
+				parameters[0] = "#1"
+			}
+			parameters[0] = fmt.Sprintf("%s:now-%s", parameters[0], timeShift)
+		}
+		parameters = append(parameters[:1], parameters[2:]...)
+	}
+	return strings.TrimRight(strings.Join(parameters, ","), ","), nil
+}
+
+// splitTriggerFunctionParameters splits the parameters of a function, the
+// quoted parameters may contain commas.
+func splitTriggerFunctionParameters(parameter string) []string {
+	parameters := []string{}
+	start := 0
+	quoted := false
+	for i := 0; i < len(parameter); i++ {
+		switch {
+		case parameter[i] == '\\' && quoted:
+			i++
+		case parameter[i] == '"':
+			quoted = !quoted
+		case parameter[i] == ',' && !quoted:
+			parameters = append(parameters, parameter[start:i])
+			start = i + 1
+		}
+	}
+	return append(parameters, parameter[start:])
+}
+
+// createTriggerExpression returns the expression in the syntax of the server,
+// so that modules written for Zabbix before 5.4 can be migrated gradually.
+func createTriggerExpression(expression, zabbixVersion string) (string, error) {
+	if isZabbixServerVersion54OrHigher(zabbixVersion) {
+		return normalizeTriggerExpression(expression)
+	}
+	return expression, nil
+}
+
+// suppressTriggerExpressionDiff ignores the differences of syntax between the
+// expressions written for Zabbix before 5.4 and the ones read from newer
+// servers. The expressions that cannot be converted always show a diff.
+func suppressTriggerExpressionDiff(k, old, new string, d *schema.ResourceData) bool {
+	normalizedOld, err := normalizeTriggerExpression(old)
+	if err != nil {
+		return false
+	}
+	normalizedNew, err := normalizeTriggerExpression(new)
+	if err != nil {
+		return false
+	}
+	return normalizedOld == normalizedNew
+}
+
 func getTriggerParentID(api *zabbix.API, id string) (string, error) {
 	triggers, err := api.TriggersGet(zabbix.Params{
 		"ouput":       "extend",
@@ -209,23 +532,44 @@ func getTriggerParentID(api *zabbix.API, id string) (string, error) {
 }
 
 func createTrigger(trigger interface{}, api *zabbix.API) (id string, err error) {
-	triggers := zabbix.Triggers{trigger.(zabbix.Trigger)}
-
-	err = api.TriggersCreate(triggers)
+	ids, err := callCreate(api, "trigger.create", []triggerObject{trigger.(triggerObject)}, "triggerids")
 	if err != nil {
 		return
 	}
-	id = triggers[0].TriggerID
+	id = ids[0]
 	return
 }
 
 func updateTrigger(trigger interface{}, api *zabbix.API) (id string, err error) {
-	triggers := zabbix.Triggers{trigger.(zabbix.Trigger)}
-
-	err = api.TriggersUpdate(triggers)
+	ids, err := callCreate(api, "trigger.update", []triggerObject{trigger.(triggerObject)}, "triggerids")
 	if err != nil {
 		return
 	}
-	id = triggers[0].TriggerID
+	id = ids[0]
+	return
+}
+
+// triggerObject extends the go-zabbix-api trigger with the fields it does not
+// support
+// https://www.zabbix.com/documentation/current/manual/api/reference/trigger/object
+type triggerObject struct {
+	zabbix.Trigger
+	RecoveryMode       string    `json:"recovery_mode"`
+	RecoveryExpression string    `json:"recovery_expression"`
+	CorrelationMode    string    `json:"correlation_mode"`
+	CorrelationTag     string    `json:"correlation_tag"`
+	ManualClose        string    `json:"manual_close"`
+	Type               string    `json:"type"`
+	URL                string    `json:"url"`
+	EventName          *string   `json:"event_name,omitempty"`
+	Opdata             *string   `json:"opdata,omitempty"`
+	Tags               []hostTag `json:"tags"`
+}
+
+func triggersGet(api *zabbix.API, params zabbix.Params) (res []triggerObject, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("trigger.get", params, &res)
 	return
 }
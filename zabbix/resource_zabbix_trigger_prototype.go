@@ -3,6 +3,7 @@ package zabbix
 import (
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 
 	"github.com/claranet/go-zabbix-api"
@@ -25,32 +26,26 @@ func resourceZabbixTriggerPrototype() *schema.Resource {
 				Required: true,
 			},
 			"expression": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "Expression of the trigger prototype, the syntax before Zabbix 5.4 is converted on newer servers.",
+				DiffSuppressFunc: suppressTriggerExpressionDiff,
 			},
 			"priority": &schema.Schema{
-				Type:     schema.TypeInt,
-				Optional: true,
-				Default:  0,
-				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
-					v := val.(int)
-					if v < 0 || v > 5 {
-						errs = append(errs, fmt.Errorf("%q, must be between 0 and 5 inclusive, got %d", key, v))
-					}
-					return
-				},
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "not_classified",
+				Description:  "Severity of the trigger, its name or its value.",
+				ValidateFunc: validateEnum(TriggerPriorities),
+				StateFunc:    enumStateFunc(TriggerPriorities),
 			},
 			"status": &schema.Schema{
-				Type:     schema.TypeInt,
-				Optional: true,
-				Default:  0,
-				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
-					v := val.(int)
-					if v < 0 || v > 1 {
-						errs = append(errs, fmt.Errorf("%q, must be between 0 and 1 inclusive, got %d", key, v))
-					}
-					return
-				},
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "enabled",
+				Description:  "Status of the trigger, its name or its value.",
+				ValidateFunc: validateEnum(TriggerStatuses),
+				StateFunc:    enumStateFunc(TriggerStatuses),
 			},
 			"dependencies": &schema.Schema{
 				Type:        schema.TypeSet,
@@ -63,13 +58,16 @@ func resourceZabbixTriggerPrototype() *schema.Resource {
 }
 
 func resourceZabbixTriggerPrototypeCreate(d *schema.ResourceData, meta interface{}) error {
-	trigger := createTriggerPrototypeObj(d)
+	trigger, err := createTriggerPrototypeObj(d, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
 
-	return createRetry(d, meta, createTriggerPrototype, trigger, resourceZabbixTriggerPrototypeRead)
+	return createRetry(d, meta, createTriggerPrototype, *trigger, resourceZabbixTriggerPrototypeRead)
 }
 
 func resourceZabbixTriggerPrototypeRead(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	params := zabbix.Params{
 		"output":             "extend",
@@ -86,12 +84,12 @@ func resourceZabbixTriggerPrototypeRead(d *schema.ResourceData, meta interface{}
 		return fmt.Errorf("Expected one result got : %d", len(res))
 	}
 	trigger := res[0]
-	err = getTriggerPrototypeExpression(&trigger, api)
+	err = getTriggerPrototypeExpression(&trigger, api, getZabbixServerVersion(meta))
 	log.Printf("[DEBUG] trigger expression: %s", trigger.Expression)
 	d.Set("description", trigger.Description)
 	d.Set("expression", trigger.Expression)
-	d.Set("priority", trigger.Priority)
-	d.Set("status", trigger.Status)
+	d.Set("priority", strconv.Itoa(int(trigger.Priority)))
+	d.Set("status", strconv.Itoa(int(trigger.Status)))
 
 	var dependencies []string
 	for _, dependencie := range trigger.Dependencies {
@@ -102,7 +100,7 @@ func resourceZabbixTriggerPrototypeRead(d *schema.ResourceData, meta interface{}
 }
 
 func resourceZabbixTriggerPrototypeExist(d *schema.ResourceData, meta interface{}) (bool, error) {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	_, err := api.TriggerPrototypeGetByID(d.Id())
 	if err != nil {
@@ -116,16 +114,20 @@ func resourceZabbixTriggerPrototypeExist(d *schema.ResourceData, meta interface{
 }
 
 func resourceZabbixTriggerPrototypeUpdate(d *schema.ResourceData, meta interface{}) error {
-	trigger := createTriggerPrototypeObj(d)
+	trigger, err := createTriggerPrototypeObj(d, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
+
 	trigger.TriggerID = d.Id()
 	if !d.HasChange("dependencies") {
 		trigger.Dependencies = nil
 	}
-	return createRetry(d, meta, updateTriggerPrototype, trigger, resourceZabbixTriggerPrototypeRead)
+	return createRetry(d, meta, updateTriggerPrototype, *trigger, resourceZabbixTriggerPrototypeRead)
 }
 
 func resourceZabbixTriggerPrototypeDelete(d *schema.ResourceData, meta interface{}) error {
-	api := meta.(*zabbix.API)
+	api := meta.(*providerMeta).api
 
 	return deleteRetry(d.Id(), getTriggerPrototypeParentID, api.TriggerPrototypesDeleteIDs, api)
 }
@@ -141,17 +143,22 @@ func createTriggerPrototypeDependencies(d *schema.ResourceData) zabbix.TriggerPr
 	return dependencies
 }
 
-func createTriggerPrototypeObj(d *schema.ResourceData) zabbix.TriggerPrototype {
-	return zabbix.TriggerPrototype{
+func createTriggerPrototypeObj(d *schema.ResourceData, zabbixVersion string) (*zabbix.TriggerPrototype, error) {
+	expression, err := createTriggerExpression(d.Get("expression").(string), zabbixVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zabbix.TriggerPrototype{
 		Description:  d.Get("description").(string),
-		Expression:   d.Get("expression").(string),
-		Priority:     zabbix.SeverityType(d.Get("priority").(int)),
-		Status:       zabbix.StatusType(d.Get("status").(int)),
+		Expression:   expression,
+		Priority:     zabbix.SeverityType(enumToInt(TriggerPriorities, d.Get("priority").(string))),
+		Status:       zabbix.StatusType(enumToInt(TriggerStatuses, d.Get("status").(string))),
 		Dependencies: createTriggerPrototypeDependencies(d),
-	}
+	}, nil
 }
 
-func getTriggerPrototypeExpression(trigger *zabbix.TriggerPrototype, api *zabbix.API) error {
+func getTriggerPrototypeExpression(trigger *zabbix.TriggerPrototype, api *zabbix.API, zabbixVersion string) error {
 	for _, function := range trigger.Functions {
 		var item zabbix.ItemPrototype
 
@@ -171,7 +178,7 @@ func getTriggerPrototypeExpression(trigger *zabbix.TriggerPrototype, api *zabbix
 			return fmt.Errorf("Expected one parent host for item with id %s, and got : %d", function.ItemID, len(item.Hosts))
 		}
 		idstr := fmt.Sprintf("{%s}", function.FunctionID)
-		expendValue := fmt.Sprintf("{%s:%s.%s(%s)}", item.Hosts[0].Host, item.Key, function.Function, function.Parameter)
+		expendValue := expandTriggerFunction(item.Hosts[0].Host, item.Key, function.Function, function.Parameter, zabbixVersion)
 		trigger.Expression = strings.Replace(trigger.Expression, idstr, expendValue, 1)
 	}
 	return nil
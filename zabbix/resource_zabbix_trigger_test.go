@@ -2,9 +2,9 @@ package zabbix
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
-	"github.com/claranet/go-zabbix-api"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
@@ -54,6 +54,183 @@ func TestAccZabbixTrigger_Basic(t *testing.T) {
 	})
 }
 
+func TestAccZabbixTrigger_Recovery(t *testing.T) {
+	resourceName := "zabbix_trigger.trigger_test"
+	strID := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixTriggerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixTriggerRecoveryConfig(strID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "recovery_mode", "recovery_expression"),
+					testAccCheckZabbixTriggerServerSyntax(resourceName, "recovery_expression", fmt.Sprintf("{template_%s:lili.lala.last()}=1", strID), fmt.Sprintf("last(/template_%s/lili.lala)=1", strID)),
+					resource.TestCheckResourceAttr(resourceName, "correlation_mode", "tag"),
+					resource.TestCheckResourceAttr(resourceName, "correlation_tag", "service"),
+					resource.TestCheckResourceAttr(resourceName, "manual_close", "true"),
+					resource.TestCheckResourceAttr(resourceName, "type", "multiple"),
+					resource.TestCheckResourceAttr(resourceName, "url", "https://wiki.example.com/runbook"),
+					resource.TestCheckResourceAttr(resourceName, "tag.#", "2"),
+				),
+			},
+			{
+				Config: testAccZabbixTriggerSimpleConfig(strID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "recovery_mode", "expression"),
+					resource.TestCheckResourceAttr(resourceName, "recovery_expression", ""),
+					resource.TestCheckResourceAttr(resourceName, "correlation_mode", "all"),
+					resource.TestCheckResourceAttr(resourceName, "manual_close", "false"),
+					resource.TestCheckResourceAttr(resourceName, "type", "single"),
+					resource.TestCheckResourceAttr(resourceName, "tag.#", "0"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccZabbixTrigger_Names(t *testing.T) {
+	resourceName := "zabbix_trigger.trigger_test"
+	strID := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixTriggerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixTriggerNamesConfig(strID, "trapper", "unsigned", "high", "disabled"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_item.item_test", "type", "2"),
+					resource.TestCheckResourceAttr("zabbix_item.item_test", "value_type", "3"),
+					resource.TestCheckResourceAttr(resourceName, "priority", "4"),
+					resource.TestCheckResourceAttr(resourceName, "status", "1"),
+				),
+			},
+			{
+				Config:   testAccZabbixTriggerNamesConfig(strID, "2", "3", "4", "1"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccZabbixTrigger_ExpressionSyntax(t *testing.T) {
+	resourceName := "zabbix_trigger.trigger_test"
+	strID := acctest.RandString(5)
+	oldSyntax := "{${zabbix_template.template_test.host}:${zabbix_item.item_test.key}.last()}=0"
+	// the syntax of the server, func(/host/key) since Zabbix 5.4
+	serverSyntax := `data.zabbix_server.compare_to_5_4_0.server_version_ge ? "last(/${zabbix_template.template_test.host}/${zabbix_item.item_test.key})=0" : "{${zabbix_template.template_test.host}:${zabbix_item.item_test.key}.last()}=0"`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixTriggerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixTriggerExpressionConfig(strID, fmt.Sprintf("%q", oldSyntax)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "description", fmt.Sprintf("trigger_%s", strID)),
+				),
+			},
+			{
+				// expressions read from 5.4+ servers match the old syntax
+				Config:   testAccZabbixTriggerExpressionConfig(strID, serverSyntax),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestNormalizeTriggerExpression(t *testing.T) {
+	cases := []struct {
+		expression string
+		want       string
+		err        string
+	}{
+		{
+			expression: "{host:agent.ping.last()}=0",
+			want:       "last(/host/agent.ping)=0",
+		},
+		{
+			expression: "{host:agent.ping.last(0)}=0 or {host:agent.ping.last(5m)}=0",
+			want:       "last(/host/agent.ping)=0 or last(/host/agent.ping)=0",
+		},
+		{
+			expression: "{host:agent.ping.last(#3)}=0",
+			want:       "last(/host/agent.ping,#3)=0",
+		},
+		{
+			expression: "{host:agent.ping.last(,1h)}=0",
+			want:       "last(/host/agent.ping,#1:now-1h)=0",
+		},
+		{
+			expression: `{host:system.cpu.load[percpu,avg1].avg(5m,1h)}>2`,
+			want:       `avg(/host/system.cpu.load[percpu,avg1],5m:now-1h)>2`,
+		},
+		{
+			expression: "{host:vfs.fs.size[/,pused].percentile(1h,,90)}>80",
+			want:       "percentile(/host/vfs.fs.size[/,pused],1h,90)>80",
+		},
+		{
+			expression: "{host:agent.ping.nodata(5m)}=1",
+			want:       "nodata(/host/agent.ping,5m)=1",
+		},
+		{
+			expression: "{host:agent.ping.count(5m)}>3",
+			want:       "count(/host/agent.ping,5m)>3",
+		},
+		{
+			expression: `{host:log[/var/log/app.log].logsource("a,b")}=1`,
+			want:       `logsource(/host/log[/var/log/app.log],"a,b")=1`,
+		},
+		{
+			expression: "last(/host/agent.ping)=0",
+			want:       "last(/host/agent.ping)=0",
+		},
+		{
+			expression: `{host:agent.version.str("4.0")}=1`,
+			err:        "str was changed by Zabbix 5.4",
+		},
+		{
+			expression: `{host:agent.version.regexp("^4")}=1`,
+			err:        "regexp was changed by Zabbix 5.4",
+		},
+		{
+			expression: "{host:agent.version.diff(0)}=1",
+			err:        "diff was changed by Zabbix 5.4",
+		},
+		{
+			expression: "{host:agent.ping.count(5m,0,eq)}>3",
+			err:        "the parameters of count were reordered by Zabbix 5.4",
+		},
+	}
+
+	for _, c := range cases {
+		got, err := normalizeTriggerExpression(c.expression)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("normalizeTriggerExpression(%q): expected error %q, got %v", c.expression, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("normalizeTriggerExpression(%q): unexpected error %s", c.expression, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("normalizeTriggerExpression(%q): got %q, expected %q", c.expression, got, c.want)
+		}
+	}
+}
+
 func TestAccZabbixTrigger_BasicMacro(t *testing.T) {
 	resourceName := "zabbix_trigger.trigger_test"
 	strID := acctest.RandString(5)
@@ -109,7 +286,7 @@ func TestAccZabbixTrigger_BasicDependencies(t *testing.T) {
 }
 
 func testAccCheckZabbixTriggerDestroy(s *terraform.State) error {
-	api := testAccProvider.Meta().(*zabbix.API)
+	api := testAccProvider.Meta().(*providerMeta).api
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "zabbix_trigger" {
@@ -166,6 +343,61 @@ func testAccZabbixTriggerSimpleConfig(strID string) string {
 	}`, strID, strID, strID, strID)
 }
 
+func testAccZabbixTriggerRecoveryConfig(strID string) string {
+	return fmt.Sprintf(`
+	data "zabbix_server" "compare_to_3_4_0" {
+		compare_version = "3.4.0"
+	}
+
+	data "zabbix_server" "compare_to_5_4_0" {
+		compare_version = "5.4.0"
+	}
+
+	resource "zabbix_host_group" "host_group_test" {
+		name = "host_group_%s"
+	}
+
+	resource "zabbix_template" "template_test" {
+		host = "template_%s"
+		groups = ["${zabbix_host_group.host_group_test.name}"]
+		description = "description for template"
+	  }
+
+	resource "zabbix_item" "item_test" {
+		name = "name_%s"
+		key = "lili.lala"
+		 delay = data.zabbix_server.compare_to_3_4_0.server_version_ge ? "0" : "15" # Zabbix 3.4+ removes this unexpectedly
+		trends = join("", ["300", data.zabbix_server.compare_to_3_4_0.unit_time_days])
+		history = join("", ["25", data.zabbix_server.compare_to_3_4_0.unit_time_days])
+		delta = data.zabbix_server.compare_to_3_4_0.server_version_ge ? 0 : 1
+		type = 2
+		description = "description for item"
+		host_id = "${zabbix_template.template_test.id}"
+	}
+
+	resource "zabbix_trigger" "trigger_test" {
+		description = "trigger_%s"
+		expression = "{${zabbix_template.template_test.host}:${zabbix_item.item_test.key}.last()}=0"
+		recovery_mode = "recovery_expression"
+		recovery_expression = "{${zabbix_template.template_test.host}:${zabbix_item.item_test.key}.last()}=1"
+		correlation_mode = "tag"
+		correlation_tag = "service"
+		manual_close = true
+		type = "multiple"
+		url = "https://wiki.example.com/runbook"
+
+		tag {
+			tag   = "service"
+			value = "lala"
+		}
+
+		tag {
+			tag = "scope"
+			value = "availability"
+		}
+	}`, strID, strID, strID, strID)
+}
+
 func testAccZabbixTriggerSimpleConfigUpdate(strID string) string {
 	return fmt.Sprintf(`
 	data "zabbix_server" "compare_to_3_4_0" {
@@ -364,3 +596,76 @@ func testAccZabbixTriggerDependencies(strID string) string {
 		]
 	}`, strID, strID, strID, strID, strID, strID)
 }
+
+func testAccZabbixTriggerNamesConfig(strID, itemType, valueType, priority, status string) string {
+	return fmt.Sprintf(`
+	resource "zabbix_host_group" "host_group_test" {
+		name = "host_group_%s"
+	}
+
+	resource "zabbix_template" "template_test" {
+		host = "template_%s"
+		groups = ["${zabbix_host_group.host_group_test.name}"]
+	}
+
+	resource "zabbix_item" "item_test" {
+		name = "name_%s"
+		key = "lili.lala"
+		delay = "0"
+		type = "%s"
+		value_type = "%s"
+		host_id = "${zabbix_template.template_test.id}"
+	}
+
+	resource "zabbix_trigger" "trigger_test" {
+		description = "trigger_%s"
+		expression = "{${zabbix_template.template_test.host}:${zabbix_item.item_test.key}.last()}=0"
+		priority = "%s"
+		status = "%s"
+	}`, strID, strID, strID, itemType, valueType, strID, priority, status)
+}
+
+func testAccZabbixTriggerExpressionConfig(strID string, expression string) string {
+	return fmt.Sprintf(`
+	data "zabbix_server" "compare_to_5_4_0" {
+		compare_version = "5.4.0"
+	}
+
+	resource "zabbix_host_group" "host_group_test" {
+		name = "host_group_%s"
+	}
+
+	resource "zabbix_template" "template_test" {
+		host = "template_%s"
+		groups = [zabbix_host_group.host_group_test.name]
+	}
+
+	resource "zabbix_item" "item_test" {
+		name = "name_%s"
+		key = "lili.lala"
+		delay = "0"
+		type = "trapper"
+		host_id = zabbix_template.template_test.id
+	}
+
+	resource "zabbix_trigger" "trigger_test" {
+		description = "trigger_%s"
+		expression = %s
+	}`, strID, strID, strID, strID, expression)
+}
+
+// testAccCheckZabbixTriggerServerSyntax checks an expression read from the
+// server, written with the func(/host/key) syntax since Zabbix 5.4.
+func testAccCheckZabbixTriggerServerSyntax(n, key, oldSyntax, newSyntax string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["data.zabbix_server.compare_to_5_4_0"]
+		if !ok {
+			return fmt.Errorf("Not found: data.zabbix_server.compare_to_5_4_0")
+		}
+		expected := oldSyntax
+		if rs.Primary.Attributes["server_version_ge"] == "true" {
+			expected = newSyntax
+		}
+		return resource.TestCheckResourceAttr(n, key, expected)(s)
+	}
+}
@@ -0,0 +1,442 @@
+package zabbix
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/claranet/go-zabbix-api"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+// UserTypes zabbix different user type, replaced by roles in Zabbix 5.4
+var UserTypes = map[string]int{
+	"user":        1,
+	"admin":       2,
+	"super_admin": 3,
+}
+
+// UserMediaSeverities zabbix different trigger severity, each one is a bit of
+// the severity of a media
+var UserMediaSeverities = map[string]int{
+	"not_classified": 0,
+	"information":    1,
+	"warning":        2,
+	"average":        3,
+	"high":           4,
+	"disaster":       5,
+}
+
+func resourceZabbixUser() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceZabbixUserCreate,
+		Read:   resourceZabbixUserRead,
+		Exists: resourceZabbixUserExists,
+		Update: resourceZabbixUserUpdate,
+		Delete: resourceZabbixUserDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceZabbixUserImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"alias": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Login name of the user, username on Zabbix 5.4+.",
+			},
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"surname": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "user",
+				Description:  "Type of the user, only used before Zabbix 5.4.",
+				ValidateFunc: validation.StringInSlice([]string{"user", "admin", "super_admin"}, false),
+			},
+			"role_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "ID of the role of the user, only used on Zabbix 5.4+.",
+			},
+			"password": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Password of the user, only sent to Zabbix when it changes.",
+			},
+			"groups": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Required:    true,
+				Description: "IDs of the user groups of the user.",
+			},
+			"media": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     schemaUserMedia(),
+				Optional: true,
+			},
+		},
+	}
+}
+
+func schemaUserMedia() *schema.Resource {
+	severities := make([]string, 0, len(UserMediaSeverities))
+	for name := range UserMediaSeverities {
+		severities = append(severities, name)
+	}
+
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"media_type_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"send_to": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Address, user name or other identifier of the recipient.",
+			},
+			"severity": &schema.Schema{
+				Type: schema.TypeSet,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(severities, false),
+				},
+				Required:    true,
+				Description: "Trigger severities to send notifications about.",
+			},
+			"period": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "1-7,00:00-24:00",
+				Description: "Time when the notifications can be sent.",
+			},
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+		},
+	}
+}
+
+func resourceZabbixUserCreate(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	user, err := createUserObj(d, api, getZabbixServerVersion(meta))
+	if err != nil {
+		return err
+	}
+	user.Password = d.Get("password").(string)
+
+	return createRetry(d, meta, createUser, *user, resourceZabbixUserRead)
+}
+
+func resourceZabbixUserRead(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+	zabbixVersion := getZabbixServerVersion(meta)
+
+	users, err := usersGet(api, zabbix.Params{
+		"userids":       d.Id(),
+		"output":        "extend",
+		"selectUsrgrps": []string{"usrgrpid"},
+		"selectMedias":  "extend",
+	})
+	if err != nil {
+		return err
+	}
+	if len(users) != 1 {
+		return fmt.Errorf("Expected one user with id %s and got %d users", d.Id(), len(users))
+	}
+	user := users[0]
+
+	if isZabbixServerVersion54OrHigher(zabbixVersion) {
+		d.Set("alias", user.Username)
+		d.Set("role_id", user.RoleID)
+	} else {
+		d.Set("alias", user.Alias)
+		for name, userType := range UserTypes {
+			if strconv.Itoa(userType) == user.Type {
+				d.Set("type", name)
+			}
+		}
+	}
+	d.Set("name", user.Name)
+	d.Set("surname", user.Surname)
+
+	groups := make([]string, len(user.UserGroups))
+	for i, group := range user.UserGroups {
+		groups[i] = group.UserGroupID
+	}
+	d.Set("groups", groups)
+
+	medias := []interface{}{}
+	if user.Medias == nil {
+		user.Medias = &[]userMedia{}
+	}
+	for _, media := range *user.Medias {
+		severity := atoi(media.Severity)
+		severities := []string{}
+		for name, bit := range UserMediaSeverities {
+			if severity&(1<<uint(bit)) != 0 {
+				severities = append(severities, name)
+			}
+		}
+
+		medias = append(medias, map[string]interface{}{
+			"media_type_id": media.MediaTypeID,
+			"send_to":       readUserMediaSendTo(media.SendTo),
+			"severity":      severities,
+			"period":        media.Period,
+			"enabled":       media.Active == "0",
+		})
+	}
+	d.Set("media", medias)
+
+	log.Printf("[DEBUG] User alias is %s\n", d.Get("alias").(string))
+	return nil
+}
+
+func resourceZabbixUserExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	api := meta.(*providerMeta).api
+
+	_, err := userGetByID(api, d.Id())
+	if err != nil {
+		if strings.Contains(err.Error(), "Expected exactly one result") {
+			log.Printf("[DEBUG] User with id %s doesn't exist", d.Id())
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func resourceZabbixUserUpdate(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+	zabbixVersion := getZabbixServerVersion(meta)
+
+	user, err := createUserObj(d, api, zabbixVersion)
+	if err != nil {
+		return err
+	}
+	if d.HasChange("password") {
+		user.Password = d.Get("password").(string)
+	}
+
+	user.UserID = d.Id()
+
+	// Before Zabbix 3.4, user.update does not accept the medias of the user
+	if user.UserMedias != nil && !isZabbixServerVersion34OrHigher(zabbixVersion) {
+		_, err = api.CallWithError("user.updatemedia", map[string]interface{}{
+			"users":  []map[string]string{{"userid": user.UserID}},
+			"medias": *user.UserMedias,
+		})
+		if err != nil {
+			return err
+		}
+		user.UserMedias = nil
+	}
+	return createRetry(d, meta, updateUser, *user, resourceZabbixUserRead)
+}
+
+func resourceZabbixUserDelete(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	return callDeleteByIDs(api, "user.delete", []string{d.Id()}, "userids")
+}
+
+// resourceZabbixUserImport accepts the id or the alias of the user
+func resourceZabbixUserImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	api := meta.(*providerMeta).api
+
+	if _, err := strconv.Atoi(d.Id()); err == nil {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	aliasKey := "alias"
+	if isZabbixServerVersion54OrHigher(getZabbixServerVersion(meta)) {
+		aliasKey = "username"
+	}
+	users, err := usersGet(api, zabbix.Params{
+		"filter": map[string]interface{}{
+			aliasKey: d.Id(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) != 1 {
+		return nil, fmt.Errorf("Expected one user with alias %s and got %d users", d.Id(), len(users))
+	}
+	d.SetId(users[0].UserID)
+	return []*schema.ResourceData{d}, nil
+}
+
+func createUserObj(d *schema.ResourceData, api *zabbix.API, zabbixVersion string) (*user, error) {
+	user := user{
+		Name:    d.Get("name").(string),
+		Surname: d.Get("surname").(string),
+	}
+
+	if isZabbixServerVersion54OrHigher(zabbixVersion) {
+		user.Username = d.Get("alias").(string)
+		user.RoleID = d.Get("role_id").(string)
+	} else {
+		if d.Get("role_id").(string) != "" {
+			return nil, fmt.Errorf("role_id is only supported from Zabbix 5.4, use type instead")
+		}
+		user.Alias = d.Get("alias").(string)
+		user.Type = strconv.Itoa(UserTypes[d.Get("type").(string)])
+	}
+
+	for _, id := range getStringSet(d, "groups") {
+		user.UserGroups = append(user.UserGroups, userGroupID{UserGroupID: id})
+	}
+
+	medias, err := createUserMedias(d, api, zabbixVersion)
+	if err != nil {
+		return nil, err
+	}
+	if isZabbixServerVersion52OrHigher(zabbixVersion) {
+		user.Medias = &medias
+	} else {
+		user.UserMedias = &medias
+	}
+	return &user, nil
+}
+
+func createUserMedias(d *schema.ResourceData, api *zabbix.API, zabbixVersion string) ([]userMedia, error) {
+	terraformMedias := d.Get("media").(*schema.Set).List()
+	medias := make([]userMedia, 0, len(terraformMedias))
+	if len(terraformMedias) == 0 {
+		return medias, nil
+	}
+
+	// Since Zabbix 3.4, the recipients of email media types are sent as an array
+	emailMediaTypes := map[string]bool{}
+	if isZabbixServerVersion34OrHigher(zabbixVersion) {
+		mediaTypes, err := mediaTypesGet(api, zabbix.Params{
+			"output": []string{"mediatypeid", "type"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, mediaType := range mediaTypes {
+			if mediaType.Type == MediaTypeTypes["email"] {
+				emailMediaTypes[mediaType.MediaTypeID] = true
+			}
+		}
+	}
+
+	for _, terraformMedia := range terraformMedias {
+		value := terraformMedia.(map[string]interface{})
+
+		severity := 0
+		for _, name := range value["severity"].(*schema.Set).List() {
+			severity |= 1 << uint(UserMediaSeverities[name.(string)])
+		}
+
+		media := userMedia{
+			MediaTypeID: value["media_type_id"].(string),
+			SendTo:      value["send_to"].(string),
+			Severity:    strconv.Itoa(severity),
+			Period:      value["period"].(string),
+			Active:      boolToString(!value["enabled"].(bool)),
+		}
+		// the recipients of email media types are read back joined with commas
+		if emailMediaTypes[media.MediaTypeID] {
+			media.SendTo = strings.Split(value["send_to"].(string), ",")
+		}
+		medias = append(medias, media)
+	}
+	return medias, nil
+}
+
+// readUserMediaSendTo returns the recipient of a media, which is an array for
+// email media types since Zabbix 3.4.
+func readUserMediaSendTo(sendTo interface{}) string {
+	switch v := sendTo.(type) {
+	case string:
+		return v
+	case []interface{}:
+		recipients := make([]string, len(v))
+		for i, recipient := range v {
+			recipients[i] = fmt.Sprintf("%v", recipient)
+		}
+		return strings.Join(recipients, ",")
+	}
+	return ""
+}
+
+func createUser(obj interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "user.create", []user{obj.(user)}, "userids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+func updateUser(obj interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "user.update", []user{obj.(user)}, "userids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+// user represent Zabbix user object
+// https://www.zabbix.com/documentation/current/manual/api/reference/user/object
+type user struct {
+	UserID     string        `json:"userid,omitempty"`
+	Alias      string        `json:"alias,omitempty"`
+	Username   string        `json:"username,omitempty"`
+	Name       string        `json:"name"`
+	Surname    string        `json:"surname"`
+	Type       string        `json:"type,omitempty"`
+	RoleID     string        `json:"roleid,omitempty"`
+	Password   string        `json:"passwd,omitempty"`
+	UserGroups []userGroupID `json:"usrgrps"`
+	UserMedias *[]userMedia  `json:"user_medias,omitempty"`
+	Medias     *[]userMedia  `json:"medias,omitempty"`
+}
+
+type userGroupID struct {
+	UserGroupID string `json:"usrgrpid"`
+}
+
+type userMedia struct {
+	MediaTypeID string      `json:"mediatypeid"`
+	SendTo      interface{} `json:"sendto"`
+	Severity    string      `json:"severity"`
+	Period      string      `json:"period"`
+	Active      string      `json:"active"`
+}
+
+func usersGet(api *zabbix.API, params zabbix.Params) (res []user, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("user.get", params, &res)
+	return
+}
+
+func userGetByID(api *zabbix.API, id string) (*user, error) {
+	users, err := usersGet(api, zabbix.Params{"userids": id})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) != 1 {
+		return nil, expectOneResult(len(users))
+	}
+	return &users[0], nil
+}
@@ -0,0 +1,302 @@
+package zabbix
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/claranet/go-zabbix-api"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+// UserGroupGUIAccesses zabbix different frontend authentication method
+var UserGroupGUIAccesses = map[string]int{
+	"default":  0,
+	"internal": 1,
+	"ldap":     2,
+	"disabled": 3,
+}
+
+// UserGroupPermissions zabbix different access level to a host group
+var UserGroupPermissions = map[string]int{
+	"deny":       0,
+	"read":       2,
+	"read_write": 3,
+}
+
+func resourceZabbixUserGroup() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceZabbixUserGroupCreate,
+		Read:   resourceZabbixUserGroupRead,
+		Exists: resourceZabbixUserGroupExists,
+		Update: resourceZabbixUserGroupUpdate,
+		Delete: resourceZabbixUserGroupDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceZabbixUserGroupImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the user group.",
+			},
+			"gui_access": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "default",
+				Description:  "Frontend authentication method of the users of the group.",
+				ValidateFunc: validation.StringInSlice([]string{"default", "internal", "ldap", "disabled"}, false),
+			},
+			"debug_mode": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"permission": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     schemaUserGroupPermission(),
+				Optional: true,
+			},
+		},
+	}
+}
+
+func schemaUserGroupPermission() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"host_group": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the host group.",
+			},
+			"permission": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"deny", "read", "read_write"}, false),
+			},
+		},
+	}
+}
+
+func resourceZabbixUserGroupCreate(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	userGroup, err := createUserGroupObj(d, api)
+	if err != nil {
+		return err
+	}
+
+	return createRetry(d, meta, createUserGroup, *userGroup, resourceZabbixUserGroupRead)
+}
+
+func resourceZabbixUserGroupRead(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	userGroups, err := userGroupsGet(api, zabbix.Params{
+		"usrgrpids":    d.Id(),
+		"output":       "extend",
+		"selectRights": "extend",
+	})
+	if err != nil {
+		return err
+	}
+	if len(userGroups) != 1 {
+		return fmt.Errorf("Expected one user group with id %s and got %d user groups", d.Id(), len(userGroups))
+	}
+	userGroup := userGroups[0]
+
+	d.Set("name", userGroup.Name)
+	for name, guiAccess := range UserGroupGUIAccesses {
+		if strconv.Itoa(guiAccess) == userGroup.GUIAccess {
+			d.Set("gui_access", name)
+		}
+	}
+	d.Set("debug_mode", userGroup.DebugMode == "1")
+	d.Set("enabled", userGroup.UsersStatus == "0")
+
+	groupIDs := make([]string, len(userGroup.Rights))
+	for i, right := range userGroup.Rights {
+		groupIDs[i] = right.ID
+	}
+	groups, err := api.HostGroupsGet(zabbix.Params{
+		"output":   "extend",
+		"groupids": groupIDs,
+	})
+	if err != nil {
+		return err
+	}
+
+	permissions := make([]interface{}, 0, len(userGroup.Rights))
+	for _, right := range userGroup.Rights {
+		permission := map[string]interface{}{}
+		for _, group := range groups {
+			if group.GroupID == right.ID {
+				permission["host_group"] = group.Name
+			}
+		}
+		for name, level := range UserGroupPermissions {
+			if strconv.Itoa(level) == right.Permission {
+				permission["permission"] = name
+			}
+		}
+		permissions = append(permissions, permission)
+	}
+	d.Set("permission", permissions)
+
+	log.Printf("[DEBUG] User group name is %s\n", userGroup.Name)
+	return nil
+}
+
+func resourceZabbixUserGroupExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	api := meta.(*providerMeta).api
+
+	_, err := userGroupGetByID(api, d.Id())
+	if err != nil {
+		if strings.Contains(err.Error(), "Expected exactly one result") {
+			log.Printf("[DEBUG] User group with id %s doesn't exist", d.Id())
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func resourceZabbixUserGroupUpdate(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	userGroup, err := createUserGroupObj(d, api)
+	if err != nil {
+		return err
+	}
+
+	userGroup.UserGroupID = d.Id()
+	return createRetry(d, meta, updateUserGroup, *userGroup, resourceZabbixUserGroupRead)
+}
+
+func resourceZabbixUserGroupDelete(d *schema.ResourceData, meta interface{}) error {
+	api := meta.(*providerMeta).api
+
+	return callDeleteByIDs(api, "usergroup.delete", []string{d.Id()}, "usrgrpids")
+}
+
+// resourceZabbixUserGroupImport accepts the id or the name of the user group
+func resourceZabbixUserGroupImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	api := meta.(*providerMeta).api
+
+	if _, err := strconv.Atoi(d.Id()); err == nil {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	userGroups, err := userGroupsGet(api, zabbix.Params{
+		"filter": map[string]interface{}{
+			"name": d.Id(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(userGroups) != 1 {
+		return nil, fmt.Errorf("Expected one user group named %s and got %d user groups", d.Id(), len(userGroups))
+	}
+	d.SetId(userGroups[0].UserGroupID)
+	return []*schema.ResourceData{d}, nil
+}
+
+func createUserGroupObj(d *schema.ResourceData, api *zabbix.API) (*userGroup, error) {
+	userGroup := userGroup{
+		Name:        d.Get("name").(string),
+		GUIAccess:   strconv.Itoa(UserGroupGUIAccesses[d.Get("gui_access").(string)]),
+		DebugMode:   boolToString(d.Get("debug_mode").(bool)),
+		UsersStatus: boolToString(!d.Get("enabled").(bool)),
+		Rights:      userGroupRights{},
+	}
+
+	terraformPermissions := d.Get("permission").(*schema.Set).List()
+	if len(terraformPermissions) == 0 {
+		return &userGroup, nil
+	}
+
+	names := make([]string, len(terraformPermissions))
+	for i, terraformPermission := range terraformPermissions {
+		names[i] = terraformPermission.(map[string]interface{})["host_group"].(string)
+	}
+	groups, err := getHostGroupsByName(api, names)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, terraformPermission := range terraformPermissions {
+		value := terraformPermission.(map[string]interface{})
+		for _, group := range groups {
+			if group.Name == value["host_group"].(string) {
+				userGroup.Rights = append(userGroup.Rights, userGroupRight{
+					ID:         group.GroupID,
+					Permission: strconv.Itoa(UserGroupPermissions[value["permission"].(string)]),
+				})
+			}
+		}
+	}
+	return &userGroup, nil
+}
+
+func createUserGroup(obj interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "usergroup.create", []userGroup{obj.(userGroup)}, "usrgrpids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+func updateUserGroup(obj interface{}, api *zabbix.API) (id string, err error) {
+	ids, err := callCreate(api, "usergroup.update", []userGroup{obj.(userGroup)}, "usrgrpids")
+	if err != nil {
+		return
+	}
+	id = ids[0]
+	return
+}
+
+// userGroup represent Zabbix user group object
+// https://www.zabbix.com/documentation/current/manual/api/reference/usergroup/object
+type userGroup struct {
+	UserGroupID string          `json:"usrgrpid,omitempty"`
+	Name        string          `json:"name"`
+	GUIAccess   string          `json:"gui_access"`
+	DebugMode   string          `json:"debug_mode"`
+	UsersStatus string          `json:"users_status"`
+	Rights      userGroupRights `json:"rights"`
+}
+
+type userGroupRight struct {
+	ID         string `json:"id"`
+	Permission string `json:"permission"`
+}
+
+type userGroupRights []userGroupRight
+
+func userGroupsGet(api *zabbix.API, params zabbix.Params) (res []userGroup, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("usergroup.get", params, &res)
+	return
+}
+
+func userGroupGetByID(api *zabbix.API, id string) (*userGroup, error) {
+	userGroups, err := userGroupsGet(api, zabbix.Params{"usrgrpids": id})
+	if err != nil {
+		return nil, err
+	}
+	if len(userGroups) != 1 {
+		return nil, expectOneResult(len(userGroups))
+	}
+	return &userGroups[0], nil
+}
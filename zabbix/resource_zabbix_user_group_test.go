@@ -0,0 +1,135 @@
+package zabbix
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccZabbixUserGroup_Basic(t *testing.T) {
+	strID := acctest.RandString(5)
+	userGroupName := fmt.Sprintf("user_group_%s", strID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixUserGroupDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixUserGroupConfig(strID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixUserGroupExists("zabbix_user_group.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_user_group.zabbix", "name", userGroupName),
+					resource.TestCheckResourceAttr("zabbix_user_group.zabbix", "gui_access", "default"),
+					resource.TestCheckResourceAttr("zabbix_user_group.zabbix", "enabled", "true"),
+					resource.TestCheckResourceAttr("zabbix_user_group.zabbix", "permission.#", "1"),
+				),
+			},
+			{
+				Config: testAccZabbixUserGroupUpdateConfig(strID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixUserGroupExists("zabbix_user_group.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_user_group.zabbix", "name", fmt.Sprintf("update_%s", userGroupName)),
+					resource.TestCheckResourceAttr("zabbix_user_group.zabbix", "gui_access", "internal"),
+					resource.TestCheckResourceAttr("zabbix_user_group.zabbix", "debug_mode", "true"),
+					resource.TestCheckResourceAttr("zabbix_user_group.zabbix", "enabled", "false"),
+					resource.TestCheckResourceAttr("zabbix_user_group.zabbix", "permission.#", "2"),
+				),
+			},
+			{
+				ResourceName:      "zabbix_user_group.zabbix",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckZabbixUserGroupDestroy(s *terraform.State) error {
+	api := testAccProvider.Meta().(*providerMeta).api
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "zabbix_user_group" {
+			continue
+		}
+
+		_, err := userGroupGetByID(api, rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("User group still exists")
+		}
+		expectedError := "Expected exactly one result, got 0."
+		if err.Error() != expectedError {
+			return fmt.Errorf("expected error : %s, got : %s", expectedError, err.Error())
+		}
+	}
+	return nil
+}
+
+func testAccCheckZabbixUserGroupExists(resource string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resource)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No record ID set")
+		}
+
+		api := testAccProvider.Meta().(*providerMeta).api
+		_, err := userGroupGetByID(api, rs.Primary.ID)
+		return err
+	}
+}
+
+func testAccZabbixUserGroupConfig(strID string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "first" {
+			name = "first_host_group_%s"
+		}
+
+		resource "zabbix_host_group" "second" {
+			name = "second_host_group_%s"
+		}
+
+		resource "zabbix_user_group" "zabbix" {
+			name = "user_group_%s"
+
+			permission {
+				host_group = zabbix_host_group.first.name
+				permission = "read"
+			}
+		}
+	`, strID, strID, strID)
+}
+
+func testAccZabbixUserGroupUpdateConfig(strID string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "first" {
+			name = "first_host_group_%s"
+		}
+
+		resource "zabbix_host_group" "second" {
+			name = "second_host_group_%s"
+		}
+
+		resource "zabbix_user_group" "zabbix" {
+			name       = "update_user_group_%s"
+			gui_access = "internal"
+			debug_mode = true
+			enabled    = false
+
+			permission {
+				host_group = zabbix_host_group.first.name
+				permission = "read_write"
+			}
+
+			permission {
+				host_group = zabbix_host_group.second.name
+				permission = "deny"
+			}
+		}
+	`, strID, strID, strID)
+}
@@ -0,0 +1,215 @@
+package zabbix
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccZabbixUser_Basic(t *testing.T) {
+	strID := acctest.RandString(5)
+	alias := fmt.Sprintf("user_%s", strID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixUserDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixUserConfig(strID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixUserExists("zabbix_user.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_user.zabbix", "alias", alias),
+					resource.TestCheckResourceAttr("zabbix_user.zabbix", "name", "John"),
+					resource.TestCheckResourceAttr("zabbix_user.zabbix", "groups.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_user.zabbix", "media.#", "1"),
+				),
+			},
+			{
+				Config: testAccZabbixUserUpdateConfig(strID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixUserExists("zabbix_user.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_user.zabbix", "alias", fmt.Sprintf("update_%s", alias)),
+					resource.TestCheckResourceAttr("zabbix_user.zabbix", "surname", "Doe"),
+					resource.TestCheckResourceAttr("zabbix_user.zabbix", "media.#", "2"),
+				),
+			},
+			{
+				ResourceName:            "zabbix_user.zabbix",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"password"},
+			},
+		},
+	})
+}
+
+func TestAccZabbixUser_EmailMedia(t *testing.T) {
+	strID := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixUserDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixUserEmailConfig(strID, "john@example.com"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixUserExists("zabbix_user.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_user.zabbix", "media.#", "1"),
+				),
+			},
+			{
+				Config: testAccZabbixUserEmailConfig(strID, "john@example.com,oncall@example.com"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckZabbixUserExists("zabbix_user.zabbix"),
+					resource.TestCheckResourceAttr("zabbix_user.zabbix", "media.#", "1"),
+				),
+			},
+			{
+				// the recipients are read back as written
+				Config:   testAccZabbixUserEmailConfig(strID, "john@example.com,oncall@example.com"),
+				PlanOnly: true,
+			},
+			{
+				ResourceName:            "zabbix_user.zabbix",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"password"},
+			},
+		},
+	})
+}
+
+func testAccCheckZabbixUserDestroy(s *terraform.State) error {
+	api := testAccProvider.Meta().(*providerMeta).api
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "zabbix_user" {
+			continue
+		}
+
+		_, err := userGetByID(api, rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("User still exists")
+		}
+		expectedError := "Expected exactly one result, got 0."
+		if err.Error() != expectedError {
+			return fmt.Errorf("expected error : %s, got : %s", expectedError, err.Error())
+		}
+	}
+	return nil
+}
+
+func testAccCheckZabbixUserExists(resource string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resource)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No record ID set")
+		}
+
+		api := testAccProvider.Meta().(*providerMeta).api
+		_, err := userGetByID(api, rs.Primary.ID)
+		return err
+	}
+}
+
+func testAccZabbixUserConfig(strID string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_user_group" "zabbix" {
+			name = "user_group_%s"
+		}
+
+		resource "zabbix_media_type" "zabbix" {
+			name = "media_type_%s"
+			script {
+				name = "notify.sh"
+			}
+		}
+
+		resource "zabbix_user" "zabbix" {
+			alias    = "user_%s"
+			name     = "John"
+			password = "Secret_%s_password"
+			groups   = [zabbix_user_group.zabbix.id]
+
+			media {
+				media_type_id = zabbix_media_type.zabbix.id
+				send_to       = "john"
+				severity      = ["high", "disaster"]
+			}
+		}
+	`, strID, strID, strID, strID)
+}
+
+func testAccZabbixUserUpdateConfig(strID string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_user_group" "zabbix" {
+			name = "user_group_%s"
+		}
+
+		resource "zabbix_media_type" "zabbix" {
+			name = "media_type_%s"
+			script {
+				name = "notify.sh"
+			}
+		}
+
+		resource "zabbix_user" "zabbix" {
+			alias    = "update_user_%s"
+			name     = "John"
+			surname  = "Doe"
+			password = "Updated_%s_password"
+			groups   = [zabbix_user_group.zabbix.id]
+
+			media {
+				media_type_id = zabbix_media_type.zabbix.id
+				send_to       = "john"
+				severity      = ["high", "disaster"]
+			}
+
+			media {
+				media_type_id = zabbix_media_type.zabbix.id
+				send_to       = "oncall"
+				severity      = ["warning", "average"]
+				period        = "1-5,09:00-18:00"
+				enabled       = false
+			}
+		}
+	`, strID, strID, strID, strID)
+}
+
+func testAccZabbixUserEmailConfig(strID, sendTo string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_user_group" "zabbix" {
+			name = "user_group_%s"
+		}
+
+		resource "zabbix_media_type" "zabbix" {
+			name = "media_type_%s"
+			email {
+				smtp_server = "mail.example.com"
+				smtp_email  = "zabbix@example.com"
+			}
+		}
+
+		resource "zabbix_user" "zabbix" {
+			alias    = "user_%s"
+			name     = "John"
+			password = "Secret_%s_password"
+			groups   = [zabbix_user_group.zabbix.id]
+
+			media {
+				media_type_id = zabbix_media_type.zabbix.id
+				send_to       = "%s"
+				severity      = ["high", "disaster"]
+			}
+		}
+	`, strID, strID, strID, strID, sendTo)
+}
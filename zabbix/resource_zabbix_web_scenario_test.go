@@ -0,0 +1,226 @@
+package zabbix
+
+import (
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccZabbixWebScenario_Basic(t *testing.T) {
+	strID := acctest.RandString(5)
+	resourceName := "zabbix_web_scenario.web_scenario_test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixWebScenarioDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixWebScenarioConfig(strID, `
+					step {
+						name         = "home"
+						url          = "https://example.com/"
+						status_codes = "200"
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", fmt.Sprintf("web_scenario_%s", strID)),
+					resource.TestCheckResourceAttr(resourceName, "delay", "1m"),
+					resource.TestCheckResourceAttr(resourceName, "retries", "1"),
+					resource.TestCheckResourceAttr(resourceName, "status", "enabled"),
+					resource.TestCheckResourceAttr(resourceName, "auth_type", "none"),
+					resource.TestCheckResourceAttr(resourceName, "step.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "step.0.url", "https://example.com/"),
+					resource.TestCheckResourceAttr(resourceName, "step.0.follow_redirects", "true"),
+					resource.TestCheckResourceAttr(resourceName, "step.0.retrieve_mode", "body"),
+					resource.TestCheckResourceAttr(resourceName, "step.0.timeout", "15s"),
+				),
+			},
+			{
+				Config: testAccZabbixWebScenarioConfig(strID, `
+					delay     = "5m"
+					retries   = 3
+					agent     = "terraform"
+					status    = "disabled"
+					auth_type = "basic"
+					username  = "user"
+					password  = "secret"
+					variables = {
+						"{token}" = "abc"
+					}
+					headers = {
+						"Accept" = "text/html"
+					}
+
+					step {
+						name          = "login"
+						url           = "https://example.com/login"
+						posts         = "user=user&token={token}"
+						required      = "Welcome"
+						retrieve_mode = "both"
+						timeout       = "30s"
+						query_field {
+							name  = "lang"
+							value = "en"
+						}
+						variables = {
+							"{session}" = "regex:session=([0-9a-z]+)"
+						}
+					}
+					step {
+						name             = "home"
+						url              = "https://example.com/"
+						status_codes     = "200,301-302"
+						follow_redirects = false
+						headers = {
+							"Cookie" = "session={session}"
+						}
+					}
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "delay", "5m"),
+					resource.TestCheckResourceAttr(resourceName, "retries", "3"),
+					resource.TestCheckResourceAttr(resourceName, "status", "disabled"),
+					resource.TestCheckResourceAttr(resourceName, "auth_type", "basic"),
+					resource.TestCheckResourceAttr(resourceName, "variables.{token}", "abc"),
+					resource.TestCheckResourceAttr(resourceName, "headers.Accept", "text/html"),
+					resource.TestCheckResourceAttr(resourceName, "step.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "step.0.name", "login"),
+					resource.TestCheckResourceAttr(resourceName, "step.0.posts", "user=user&token={token}"),
+					resource.TestCheckResourceAttr(resourceName, "step.0.query_field.0.name", "lang"),
+					resource.TestCheckResourceAttr(resourceName, "step.0.variables.{session}", "regex:session=([0-9a-z]+)"),
+					resource.TestCheckResourceAttr(resourceName, "step.0.retrieve_mode", "both"),
+					resource.TestCheckResourceAttr(resourceName, "step.1.name", "home"),
+					resource.TestCheckResourceAttr(resourceName, "step.1.follow_redirects", "false"),
+					resource.TestCheckResourceAttr(resourceName, "step.1.headers.Cookie", "session={session}"),
+				),
+			},
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"password"},
+			},
+		},
+	})
+}
+
+func TestAccZabbixWebScenario_StepOrder(t *testing.T) {
+	strID := acctest.RandString(5)
+	resourceName := "zabbix_web_scenario.web_scenario_test"
+	var webScenarioID string
+	steps := `
+		step {
+			name = "first"
+			url  = "https://example.com/first"
+		}
+		step {
+			name = "second"
+			url  = "https://example.com/second"
+		}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckZabbixWebScenarioDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccZabbixWebScenarioConfig(strID, steps),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckResourceID(resourceName, &webScenarioID),
+					resource.TestCheckResourceAttr(resourceName, "step.0.name", "first"),
+					resource.TestCheckResourceAttr(resourceName, "step.1.name", "second"),
+				),
+			},
+			{
+				PreConfig:          testAccZabbixWebScenarioReverseSteps(&webScenarioID),
+				Config:             testAccZabbixWebScenarioConfig(strID, steps),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccZabbixWebScenarioConfig(strID, steps),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "step.0.name", "first"),
+					resource.TestCheckResourceAttr(resourceName, "step.1.name", "second"),
+				),
+			},
+		},
+	})
+}
+
+// testAccZabbixWebScenarioReverseSteps reverses the steps of the web scenario
+// outside of terraform
+func testAccZabbixWebScenarioReverseSteps(id *string) func() {
+	return func() {
+		api := testAccProvider.Meta().(*providerMeta).api
+
+		webScenario, err := webScenarioGetByID(api, *id)
+		if err != nil {
+			log.Print(err)
+			return
+		}
+
+		steps := make([]map[string]interface{}, len(webScenario.Steps))
+		for i, step := range webScenario.Steps {
+			steps[i] = map[string]interface{}{
+				"httpstepid": step.HTTPStepID,
+				"name":       step.Name,
+				"url":        step.URL,
+				"no":         len(webScenario.Steps) - atoi(step.No) + 1,
+			}
+		}
+		_, err = callCreate(api, "httptest.update", []map[string]interface{}{{
+			"httptestid": *id,
+			"steps":      steps,
+		}}, "httptestids")
+		if err != nil {
+			log.Print(err)
+		}
+	}
+}
+
+func testAccCheckZabbixWebScenarioDestroy(s *terraform.State) error {
+	api := testAccProvider.Meta().(*providerMeta).api
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "zabbix_web_scenario" {
+			continue
+		}
+
+		_, err := webScenarioGetByID(api, rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Web scenario still exists %s", rs.Primary.ID)
+		}
+
+		expectedError := "Expected exactly one result, got 0."
+		if err.Error() != expectedError {
+			return fmt.Errorf("expected error : %s, got : %s", expectedError, err.Error())
+		}
+	}
+	return nil
+}
+
+func testAccZabbixWebScenarioConfig(strID string, webScenario string) string {
+	return fmt.Sprintf(`
+		resource "zabbix_host_group" "host_group_test" {
+			name = "host_group_%s"
+		}
+
+		resource "zabbix_template" "template_test" {
+			host = "template_%s"
+			groups = [zabbix_host_group.host_group_test.name]
+		}
+
+		resource "zabbix_web_scenario" "web_scenario_test" {
+			name = "web_scenario_%s"
+			host_id = zabbix_template.template_test.id
+			%s
+		}
+	`, strID, strID, strID, webScenario)
+}